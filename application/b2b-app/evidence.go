@@ -0,0 +1,192 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// CompletionEvidence is passed to SubmitCompletion in the transient map, so that only its hash ends up on the ledger.
+type CompletionEvidence struct {
+	Files             []EvidenceFile `json:"Files"`
+	Latitude          float64        `json:"Latitude"`
+	Longitude         float64        `json:"Longitude"`
+	PartSerialNumbers []string       `json:"PartSerialNumbers"`
+}
+
+type EvidenceFile struct {
+	Name   string `json:"Name"`
+	Kind   string `json:"Kind"`
+	SHA256 string `json:"SHA256"`
+}
+
+type EvidenceSummary struct {
+	Collection   string   `json:"Collection"`
+	EvidenceHash string   `json:"EvidenceHash"`
+	FileHashes   []string `json:"FileHashes"`
+}
+
+// evidenceStorePath is the directory of the content-addressed store the evidence files are kept in,
+// every file is stored under its SHA-256 hash. EVIDENCE_STORE can be set to use another directory.
+func evidenceStorePath() string {
+	if storePath := os.Getenv("EVIDENCE_STORE"); storePath != "" {
+		return storePath
+	}
+	return "./evidence"
+}
+
+// storeEvidenceFile saves an uploaded file in the evidence store and returns its SHA-256 hash.
+func storeEvidenceFile(fileHeader *multipart.FileHeader) (string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	storePath := evidenceStorePath()
+	err = os.MkdirAll(storePath, 0o750)
+	if err != nil {
+		return "", err
+	}
+	tmpFile, err := os.CreateTemp(storePath, "upload-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	hasher := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmpFile, hasher), file)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	err = os.Rename(tmpFile.Name(), filepath.Join(storePath, hash))
+	if err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// readCompletionEvidence stores the photos and logs of a multipart completion request and returns the evidence to submit.
+func readCompletionEvidence(c *gin.Context) (*CompletionEvidence, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+
+	evidence := CompletionEvidence{
+		Files:             []EvidenceFile{},
+		PartSerialNumbers: form.Value["partSerialNumber"],
+	}
+	if evidence.PartSerialNumbers == nil {
+		evidence.PartSerialNumbers = []string{}
+	}
+	evidence.Latitude, err = strconv.ParseFloat(c.PostForm("latitude"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid latitude: %w", err)
+	}
+	evidence.Longitude, err = strconv.ParseFloat(c.PostForm("longitude"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid longitude: %w", err)
+	}
+
+	for _, kind := range []string{"photo", "log"} {
+		for _, fileHeader := range form.File[kind] {
+			hash, err := storeEvidenceFile(fileHeader)
+			if err != nil {
+				return nil, err
+			}
+			evidence.Files = append(evidence.Files, EvidenceFile{Name: fileHeader.Filename, Kind: kind, SHA256: hash})
+		}
+	}
+
+	return &evidence, nil
+}
+
+// CompleteJobHandler submits a job as completed together with its evidence. The request is a multipart form
+// with the jobId, the latitude and longitude, any number of photo and log files and partSerialNumber values.
+func CompleteJobHandler(c *gin.Context) {
+	clientConnection := newGrpcConnection()
+	defer clientConnection.Close()
+
+	id := newIdentity()
+	sign := newSign()
+
+	// Create a Gateway connection for a specific client identity
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithClientConnection(clientConnection),
+		// Default timeouts for different gRPC calls
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	defer gw.Close()
+
+	// Override default values for chaincode and channel name as they may differ in testing contexts.
+	chaincodeName := "gc"
+	if ccname := os.Getenv("CHAINCODE_NAME"); ccname != "" {
+		chaincodeName = ccname
+	}
+
+	channelName := "mychannel"
+	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
+		channelName = cname
+	}
+
+	network := gw.GetNetwork(channelName)
+
+	contract := network.GetContract(chaincodeName)
+
+	jobID := c.PostForm("jobId")
+	if jobID == "" {
+		c.JSON(400, gin.H{"error": "jobId is required"})
+		return
+	}
+	evidence, err := readCompletionEvidence(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	evidenceJSON, err := json.Marshal(evidence)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	fmt.Printf("\n--> Submit Transaction: SubmitCompletion, function submits job %s as completed with %d evidence files\n", jobID, len(evidence.Files))
+	submitResult, err := submit(contract, "SubmitCompletion", client.WithArguments(jobID), client.WithTransient(map[string][]byte{"evidence": evidenceJSON}))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var job Job
+	err = json.Unmarshal(submitResult, &job)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "job submitted as completed", "job": job})
+}
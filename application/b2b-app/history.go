@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+type JobVersion struct {
+	TxID      string    `json:"TxID"`
+	Timestamp time.Time `json:"Timestamp"`
+	IsDelete  bool      `json:"IsDelete"`
+	Job       *Job      `json:"Job,omitempty"`
+}
+
+type GeneralContractVersion struct {
+	TxID            string           `json:"TxID"`
+	Timestamp       time.Time        `json:"Timestamp"`
+	IsDelete        bool             `json:"IsDelete"`
+	GeneralContract *GeneralContract `json:"GeneralContract,omitempty"`
+}
+
+func getJobHistory(contract *client.Contract, jobID string) ([]JobVersion, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetJobHistory, function returns every version of a job\n")
+
+	evaluateResult, err := contract.EvaluateTransaction("GetJobHistory", technichianID, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []JobVersion
+	err = json.Unmarshal(evaluateResult, &versions)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func getGeneralContractHistory(contract *client.Contract) ([]GeneralContractVersion, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetGeneralContractHistory, function returns every version of the general contract\n")
+
+	evaluateResult, err := contract.EvaluateTransaction("GetGeneralContractHistory", technichianID)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []GeneralContractVersion
+	err = json.Unmarshal(evaluateResult, &versions)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// JobHistoryHandler returns every version of a job of the technician, oldest first.
+func JobHistoryHandler(c *gin.Context) {
+	clientConnection := newGrpcConnection()
+	defer clientConnection.Close()
+
+	id := newIdentity()
+	sign := newSign()
+
+	// Create a Gateway connection for a specific client identity
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithClientConnection(clientConnection),
+		// Default timeouts for different gRPC calls
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	defer gw.Close()
+
+	// Override default values for chaincode and channel name as they may differ in testing contexts.
+	chaincodeName := "gc"
+	if ccname := os.Getenv("CHAINCODE_NAME"); ccname != "" {
+		chaincodeName = ccname
+	}
+
+	channelName := "mychannel"
+	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
+		channelName = cname
+	}
+
+	network := gw.GetNetwork(channelName)
+
+	contract := network.GetContract(chaincodeName)
+	versions, err := getJobHistory(contract, c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, versions)
+}
+
+// GeneralContractHistoryHandler returns every version of the general contract of the technician, oldest first.
+func GeneralContractHistoryHandler(c *gin.Context) {
+	clientConnection := newGrpcConnection()
+	defer clientConnection.Close()
+
+	id := newIdentity()
+	sign := newSign()
+
+	// Create a Gateway connection for a specific client identity
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithClientConnection(clientConnection),
+		// Default timeouts for different gRPC calls
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	defer gw.Close()
+
+	// Override default values for chaincode and channel name as they may differ in testing contexts.
+	chaincodeName := "gc"
+	if ccname := os.Getenv("CHAINCODE_NAME"); ccname != "" {
+		chaincodeName = ccname
+	}
+
+	channelName := "mychannel"
+	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
+		channelName = cname
+	}
+
+	network := gw.GetNetwork(channelName)
+
+	contract := network.GetContract(chaincodeName)
+	versions, err := getGeneralContractHistory(contract)
+	if err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, versions)
+}
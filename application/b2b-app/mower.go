@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+func getJobsByMower(contract *client.Contract, serialNumber string) ([]Job, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetJobsByMower, function returns every job of a mower in one service\n")
+
+	evaluateResult, err := contract.EvaluateTransaction("GetJobsByMower", serialNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []Job
+	err = json.Unmarshal(evaluateResult, &jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// MowerJobsHandler returns the jobs done on a mower, by the chaincode name of their service. The serial
+// number of the mower an SLA covers is the MowerSerial of the SLA.
+func MowerJobsHandler(c *gin.Context) {
+	clientConnection := newGrpcConnection()
+	defer clientConnection.Close()
+
+	id := newIdentity()
+	sign := newSign()
+
+	// Create a Gateway connection for a specific client identity
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithClientConnection(clientConnection),
+		// Default timeouts for different gRPC calls
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	defer gw.Close()
+
+	// Override default values for chaincode and channel name as they may differ in testing contexts.
+	chaincodeName := "gc"
+	if ccname := os.Getenv("CHAINCODE_NAME"); ccname != "" {
+		chaincodeName = ccname
+	}
+
+	channelName := "mychannel"
+	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
+		channelName = cname
+	}
+
+	network := gw.GetNetwork(channelName)
+
+	jobTypes, err := listJobTypes(network.GetContract(chaincodeName))
+	if err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	serialNumber := c.Param("serial")
+	jobs := map[string][]Job{}
+	for _, jobType := range jobTypes {
+		if _, ok := jobs[jobType.ChaincodeName]; ok {
+			continue
+		}
+		serviceJobs, err := getJobsByMower(network.GetContract(jobType.ChaincodeName), serialNumber)
+		if err != nil {
+			c.IndentedJSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		jobs[jobType.ChaincodeName] = serviceJobs
+	}
+	c.IndentedJSON(http.StatusOK, jobs)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// The owner operations are submitted as the owner-admin of Org2, the organisation that owns the services.
+const (
+	ownerMSPID        = "Org2MSP"
+	ownerCryptoPath   = "../../test-network/organizations/peerOrganizations/org2.example.com"
+	ownerCertPath     = ownerCryptoPath + "/users/User1@org2.example.com/msp/signcerts/User1@org2.example.com-cert.pem"
+	ownerKeyPath      = ownerCryptoPath + "/users/User1@org2.example.com/msp/keystore/"
+	ownerTLSCertPath  = ownerCryptoPath + "/peers/peer0.org2.example.com/tls/ca.crt"
+	ownerPeerEndpoint = "localhost:9051"
+	ownerGatewayPeer  = "peer0.org2.example.com"
+)
+
+type ReassignJobParams struct {
+	TechnicianID    string `json:"TechnicianID"`
+	JobID           string `json:"JobID"`
+	NewTechnicianID string `json:"NewTechnicianID"`
+}
+
+type RecordInspectionParams struct {
+	TechnicianID   string   `json:"TechnicianID"`
+	JobID          string   `json:"JobID"`
+	Verdict        string   `json:"Verdict"`
+	FaultCode      string   `json:"FaultCode"`
+	Notes          string   `json:"Notes"`
+	EvidenceHashes []string `json:"EvidenceHashes"`
+}
+
+type CancelJobParams struct {
+	TechnicianID string `json:"TechnicianID"`
+	JobID        string `json:"JobID"`
+	Compensation int    `json:"Compensation"`
+}
+
+// newOwnerGrpcConnection creates a gRPC connection to the Gateway server of the owner organisation.
+func newOwnerGrpcConnection() *grpc.ClientConn {
+	certificate, err := loadCertificate(ownerTLSCertPath)
+	if err != nil {
+		panic(err)
+	}
+
+	certPool := x509.NewCertPool()
+	certPool.AddCert(certificate)
+	transportCredentials := credentials.NewClientTLSFromCert(certPool, ownerGatewayPeer)
+
+	connection, err := grpc.Dial(ownerPeerEndpoint, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		panic(fmt.Errorf("failed to create gRPC connection: %w", err))
+	}
+
+	return connection
+}
+
+// newOwnerIdentity creates the owner-admin client identity.
+func newOwnerIdentity() *identity.X509Identity {
+	certificate, err := loadCertificate(ownerCertPath)
+	if err != nil {
+		panic(err)
+	}
+
+	id, err := identity.NewX509Identity(ownerMSPID, certificate)
+	if err != nil {
+		panic(err)
+	}
+
+	return id
+}
+
+// newOwnerSign creates a function that signs with the private key of the owner-admin.
+func newOwnerSign() identity.Sign {
+	files, err := os.ReadDir(ownerKeyPath)
+	if err != nil {
+		panic(fmt.Errorf("failed to read private key directory: %w", err))
+	}
+	privateKeyPEM, err := os.ReadFile(path.Join(ownerKeyPath, files[0].Name()))
+	if err != nil {
+		panic(fmt.Errorf("failed to read private key file: %w", err))
+	}
+
+	privateKey, err := identity.PrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		panic(err)
+	}
+
+	sign, err := identity.NewPrivateKeySign(privateKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return sign
+}
+
+// ownerContract connects to the job contract as the owner-admin. The caller closes the returned gateway and connection.
+func ownerContract() (*client.Contract, *client.Gateway, *grpc.ClientConn) {
+	clientConnection := newOwnerGrpcConnection()
+
+	gw, err := client.Connect(
+		newOwnerIdentity(),
+		client.WithSign(newOwnerSign()),
+		client.WithClientConnection(clientConnection),
+		// Default timeouts for different gRPC calls
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		clientConnection.Close()
+		panic(err)
+	}
+
+	// Override default values for chaincode and channel name as they may differ in testing contexts.
+	chaincodeName := "gc"
+	if ccname := os.Getenv("CHAINCODE_NAME"); ccname != "" {
+		chaincodeName = ccname
+	}
+
+	channelName := "mychannel"
+	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
+		channelName = cname
+	}
+
+	return gw.GetNetwork(channelName).GetContract(chaincodeName), gw, clientConnection
+}
+
+// ReassignJobHandler moves a job that has not been completed to another technician.
+func ReassignJobHandler(c *gin.Context) {
+	contract, gw, clientConnection := ownerContract()
+	defer clientConnection.Close()
+	defer gw.Close()
+
+	var params ReassignJobParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	fmt.Printf("\n--> Submit Transaction: ReassignJob, function moves job %s from %s to %s\n", params.JobID, params.TechnicianID, params.NewTechnicianID)
+	submitResult, err := submitTransaction(contract, "ReassignJob", params.TechnicianID, params.JobID, params.NewTechnicianID)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var job Job
+	err = json.Unmarshal(submitResult, &job)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "reassigned job", "job": job})
+}
+
+// CancelJobHandler cancels a job that has not been completed, optionally compensating the technician.
+func CancelJobHandler(c *gin.Context) {
+	contract, gw, clientConnection := ownerContract()
+	defer clientConnection.Close()
+	defer gw.Close()
+
+	var params CancelJobParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	fmt.Printf("\n--> Submit Transaction: CancelJob, function cancels job %s of %s\n", params.JobID, params.TechnicianID)
+	submitResult, err := submitTransaction(contract, "CancelJob", params.TechnicianID, params.JobID, strconv.Itoa(params.Compensation))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var job Job
+	err = json.Unmarshal(submitResult, &job)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "cancelled job", "job": job})
+}
+
+// RecordInspectionHandler records the inspection of a job awaiting inspection, which decides the payout of the job.
+func RecordInspectionHandler(c *gin.Context) {
+	contract, gw, clientConnection := ownerContract()
+	defer clientConnection.Close()
+	defer gw.Close()
+
+	var params RecordInspectionParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if params.EvidenceHashes == nil {
+		params.EvidenceHashes = []string{}
+	}
+	evidenceHashes, err := json.Marshal(params.EvidenceHashes)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	fmt.Printf("\n--> Submit Transaction: RecordInspection, function records the inspection of job %s of %s\n", params.JobID, params.TechnicianID)
+	submitResult, err := submitTransaction(contract, "RecordInspection", params.TechnicianID, params.JobID, params.Verdict, params.FaultCode, params.Notes, string(evidenceHashes))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var job Job
+	err = json.Unmarshal(submitResult, &job)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "recorded inspection", "job": job})
+}
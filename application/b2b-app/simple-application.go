@@ -49,21 +49,92 @@ type Contract struct {
 	Contract *client.Contract
 }
 type Job struct {
-	Type          string    `json:"Type"`
-	Status        string    `json:"Status"`
+	Type           string            `json:"Type"`
+	Status         string            `json:"Status"`
+	JobPay         int               `json:"JobPay"`
+	InspectionPay  int               `json:"InspectionPay"`
+	Deadline       time.Time         `json:"Deadline,omitempty"`
+	ID             string            `json:"ID"`
+	Mower          string            `json:"Mower"`
+	Address        string            `json:"Address"`
+	Transitions    []JobTransition   `json:"Transitions"`
+	ServiceLevel   string            `json:"ServiceLevel"`
+	SLAID          string            `json:"SLAID,omitempty"`
+	Overdue        bool              `json:"Overdue"`
+	CompletedAt    *time.Time        `json:"CompletedAt,omitempty"`
+	DaysLate       int               `json:"DaysLate"`
+	Penalty        int               `json:"Penalty"`
+	Payout         int               `json:"Payout"`
+	PayoutPeriod   string            `json:"PayoutPeriod,omitempty"`
+	EventType      string            `json:"EventType,omitempty"`
+	ReassignedFrom string            `json:"ReassignedFrom,omitempty"`
+	RateVersion    int               `json:"RateVersion"`
+	Inspection     *InspectionReport `json:"Inspection,omitempty"`
+	Evidence       *EvidenceSummary  `json:"Evidence,omitempty"`
+}
+
+type InspectionReport struct {
+	Verdict        string    `json:"Verdict"`
+	FaultCode      string    `json:"FaultCode"`
+	Notes          string    `json:"Notes"`
+	EvidenceHashes []string  `json:"EvidenceHashes"`
+	InspectorMSPID string    `json:"InspectorMSPID"`
+	InspectorID    string    `json:"InspectorID"`
+	InspectedAt    time.Time `json:"InspectedAt"`
+}
+
+type JobType struct {
+	EventType     string       `json:"EventType"`
+	ChaincodeName string       `json:"ChaincodeName"`
+	DisplayName   string       `json:"DisplayName"`
+	DeadlineDays  DeadlineDays `json:"DeadlineDays"`
+	Deprecated    bool         `json:"Deprecated"`
+}
+
+type DeadlineDays struct {
+	Standard int `json:"Standard"`
+	Gold     int `json:"Gold"`
+	Platinum int `json:"Platinum"`
+}
+
+type RateCard struct {
+	Version       int       `json:"Version"`
 	JobPay        int       `json:"JobPay"`
 	InspectionPay int       `json:"InspectionPay"`
-	Deadline      time.Time `json:"Deadline,omitempty"`
-	ID            string    `json:"ID"`
-	Mower         string    `json:"Mower"`
-	Address       string    `json:"Adress"`
+	EffectiveFrom time.Time `json:"EffectiveFrom"`
+	SetAt         time.Time `json:"SetAt"`
+	SetBy         string    `json:"SetBy"`
+}
+
+type Rates struct {
+	Current  *RateCard   `json:"Current"`
+	Upcoming []*RateCard `json:"Upcoming"`
+}
+
+type JobTransition struct {
+	From    string    `json:"From"`
+	To      string    `json:"To"`
+	ByMSPID string    `json:"ByMSPID"`
+	ByID    string    `json:"ByID"`
+	ByRole  string    `json:"ByRole"`
+	At      time.Time `json:"At"`
+	TxID    string    `json:"TxID"`
 }
 
 type GeneralContract struct {
-	TechnicianID   string   `json:"TechnicianID"`
-	MonthlyBalance int      `json:"MonthlyBalance"`
-	Jobs           []Job    `json:"Jobs"`
-	JobAuthority   []string `json:"JobAuthority"`
+	TechnicianID       string   `json:"TechnicianID"`
+	MonthlyBalance     int      `json:"MonthlyBalance"`
+	CarriedOverBalance int      `json:"CarriedOverBalance,omitempty"`
+	JobAuthority       []string `json:"JobAuthority"`
+	UnbilledJobs       []string `json:"UnbilledJobs"`
+	LastPayoutPeriod   string   `json:"LastPayoutPeriod,omitempty"`
+	Jobs               []Job    `json:"Jobs,omitempty"`
+}
+
+type JobPage struct {
+	Jobs                []Job  `json:"Jobs"`
+	Bookmark            string `json:"Bookmark"`
+	FetchedRecordsCount int32  `json:"FetchedRecordsCount"`
 }
 
 type TakeJobParams struct {
@@ -197,11 +268,21 @@ func CreateRouter() *gin.Engine {
 
 	r.GET("/gc", ReadGCHandler)
 	r.GET("/gc/jobs", GetAllJobsHandler)
+	r.GET("/gc/history", GeneralContractHistoryHandler)
+	r.GET("/job/:id/history", JobHistoryHandler)
+	r.GET("/jobtypes", ListJobTypesHandler)
+	r.GET("/rates/:service", GetRatesHandler)
+	r.GET("/mower/:serial/jobs", MowerJobsHandler)
 	r.POST("/gc/create", CreateHandler)
-	r.POST("/job/create", CreateJobHandler)
 	r.POST("/job/take", TakeJobHandler)
-	r.POST("/job/done_correct", FinishJobCorrectErrorHandler)
-	r.POST("/job/done_wrong", FinishJobWrongErrorHandler)
+	r.POST("/job/enroute", JobTransitionHandler("StartTravel", "job marked as en route"))
+	r.POST("/job/start", JobTransitionHandler("StartWork", "job marked as in progress"))
+	r.POST("/job/complete", CompleteJobHandler)
+	r.POST("/job/fail", JobTransitionHandler("FailJob", "job marked as failed"))
+	r.POST("/job/release", ReleaseJobHandler)
+	r.POST("/owner/job/reassign", ReassignJobHandler)
+	r.POST("/owner/job/cancel", CancelJobHandler)
+	r.POST("/owner/job/inspect", RecordInspectionHandler)
 	return r
 }
 
@@ -291,46 +372,23 @@ func CreateHandler(c *gin.Context) {
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "General contract created"})
 }
 
-func createJob(contract *client.Contract, jobID string) {
-	fmt.Println("\n--> Submit Transaction: UpdateAsset asset70, asset70 does not exist and should return an error")
+// takeJob submits the signed work order to TakeJob. The technician is taken from the identity that signs
+// the transaction.
+func takeJob(contract *client.Contract, workOrder *SignedWorkOrder) error {
+	fmt.Println("\n--> Submit Transaction: TakeJob, function updates a key value pair on the ledger")
 
-	_, err := contract.SubmitTransaction("Create", technichianID, jobID, "5", "Tomoko", "300")
+	fmt.Println("work order: ", workOrder.Attestation)
 
-	switch err := err.(type) {
-	case *client.EndorseError:
-		fmt.Printf("Endorse error for transaction %s with gRPC status %v: %s\n", err.TransactionID, status.Code(err), err)
-	case *client.SubmitError:
-		fmt.Printf("Submit error for transaction %s with gRPC status %v: %s\n", err.TransactionID, status.Code(err), err)
-	case *client.CommitStatusError:
-		if errors.Is(err, context.DeadlineExceeded) {
-			fmt.Printf("Timeout waiting for transaction %s commit status: %s", err.TransactionID, err)
-		} else {
-			fmt.Printf("Error obtaining commit status for transaction %s with gRPC status %v: %s\n", err.TransactionID, status.Code(err), err)
-		}
-	case *client.CommitError:
-		fmt.Printf("Transaction %s failed to commit with status %d: %s\n", err.TransactionID, int32(err.Code), err)
-	default:
-		panic(fmt.Errorf("unexpected error type %T: %w", err, err))
+	submitResult, err := submitTransaction(contract, "TakeJob", workOrder.Attestation, workOrder.Signature)
+	if err != nil {
+		return err
 	}
 
-	// Any error that originates from a peer or orderer node external to the gateway will have its details
-	// embedded within the gRPC status error. The following code shows how to extract that.
-	statusErr := status.Convert(err)
-
-	details := statusErr.Details()
-	if len(details) > 0 {
-		fmt.Println("Error Details:")
-
-		for _, detail := range details {
-			switch detail := detail.(type) {
-			case *gateway.ErrorDetail:
-				fmt.Printf("- address: %s, mspId: %s, message: %s\n", detail.Address, detail.MspId, detail.Message)
-			}
-		}
-	}
+	fmt.Println("Result:", submitResult)
+	return nil
 }
 
-func CreateJobHandler(c *gin.Context) {
+func TakeJobHandler(c *gin.Context) {
 	clientConnection := newGrpcConnection()
 	defer clientConnection.Close()
 
@@ -373,19 +431,40 @@ func CreateJobHandler(c *gin.Context) {
 	network := gw.GetNetwork(channelName)
 
 	contract := network.GetContract(chaincodeName)
-	createJob(contract, c.Param("jobID"))
-	c.IndentedJSON(http.StatusOK, gin.H{"message": "job created"})
-}
 
-// Submit a transaction to query ledger state.
-func takeJob(contract *client.Contract, jobID string) {
-	fmt.Println("\n--> Submit Transaction: TakeJob, function updates a key value pair on the ledger \n")
+	var params TakeJobParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	workOrder, err := getSignedWorkOrder(params.JobID)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	err = takeJob(contract, workOrder)
+	if err != nil {
+		// the chaincode rejecting the job is an endorsement error, anything else is a failure of the network
+		code := http.StatusInternalServerError
+		var endorseErr *client.EndorseError
+		if errors.As(err, &endorseErr) {
+			code = http.StatusBadRequest
+		}
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Job added to your general contract."})
+}
 
-	fmt.Println("jobID: ", jobID)
+// submitTransaction submits a transaction and prints the details of any error from the network.
+func submitTransaction(contract *client.Contract, transaction string, args ...string) ([]byte, error) {
+	return submit(contract, transaction, client.WithArguments(args...))
+}
 
-	//Remember to remove jobtype when integrated with jespers system
-	submitResult, err := contract.SubmitTransaction("TakeJob", jobID, technichianID)
-	fmt.Println("err: ", err, status.Code(err))
+// submit submits a transaction with the given proposal options, such as transient data, and prints
+// the details of any error from the network.
+func submit(contract *client.Contract, transaction string, options ...client.ProposalOption) ([]byte, error) {
+	submitResult, err := contract.Submit(transaction, options...)
 	if err != nil {
 		switch err := err.(type) {
 		case *client.EndorseError:
@@ -401,7 +480,7 @@ func takeJob(contract *client.Contract, jobID string) {
 		case *client.CommitError:
 			fmt.Printf("Transaction %s failed to commit with status %d: %s\n", err.TransactionID, int32(err.Code), err)
 		default:
-			panic(fmt.Errorf("unexpected error type %T: %w", err, err))
+			return nil, fmt.Errorf("unexpected error type %T: %w", err, err)
 		}
 
 		// Any error that originates from a peer or orderer node external to the gateway will have its details
@@ -419,19 +498,92 @@ func takeJob(contract *client.Contract, jobID string) {
 				}
 			}
 		}
+		return nil, err
 	}
 
-	fmt.Println("Result:", submitResult)
+	return submitResult, nil
 }
 
-func TakeJobHandler(c *gin.Context) {
+// submitJobTransition submits one of the job lifecycle transactions that move a job to its next status.
+func submitJobTransition(contract *client.Contract, transaction string, jobID string) (*Job, error) {
+	fmt.Printf("\n--> Submit Transaction: %s, function updates the status of a job\n", transaction)
+
+	submitResult, err := submitTransaction(contract, transaction, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var job Job
+	err = json.Unmarshal(submitResult, &job)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Result:", job)
+	return &job, nil
+}
+
+// JobTransitionHandler returns a handler that lets the technician report progress on a job
+// by submitting the given lifecycle transaction.
+func JobTransitionHandler(transaction string, message string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientConnection := newGrpcConnection()
+		defer clientConnection.Close()
+
+		id := newIdentity()
+		sign := newSign()
+
+		// Create a Gateway connection for a specific client identity
+		gw, err := client.Connect(
+			id,
+			client.WithSign(sign),
+			client.WithClientConnection(clientConnection),
+			// Default timeouts for different gRPC calls
+			client.WithEvaluateTimeout(5*time.Second),
+			client.WithEndorseTimeout(15*time.Second),
+			client.WithSubmitTimeout(5*time.Second),
+			client.WithCommitStatusTimeout(1*time.Minute),
+		)
+		if err != nil {
+			panic(err)
+		}
+
+		defer gw.Close()
+
+		// Override default values for chaincode and channel name as they may differ in testing contexts.
+		chaincodeName := "gc"
+		if ccname := os.Getenv("CHAINCODE_NAME"); ccname != "" {
+			chaincodeName = ccname
+		}
+
+		channelName := "mychannel"
+		if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
+			channelName = cname
+		}
+
+		network := gw.GetNetwork(channelName)
+
+		contract := network.GetContract(chaincodeName)
+
+		var params JobDoneParams
+		if err := c.ShouldBindJSON(&params); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		job, err := submitJobTransition(contract, transaction, params.JobID)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.IndentedJSON(http.StatusOK, gin.H{"message": message, "job": job})
+	}
+}
+
+// ReleaseJobHandler gives back a job the technician can not do so that another technician can take it.
+func ReleaseJobHandler(c *gin.Context) {
 	clientConnection := newGrpcConnection()
 	defer clientConnection.Close()
 
 	id := newIdentity()
-	id1 := id.Credentials()
-	fmt.Println("id1: ", string(id1[:]))
-	fmt.Println("mspID: ", id.MspID())
 	sign := newSign()
 
 	// Create a Gateway connection for a specific client identity
@@ -457,8 +609,6 @@ func TakeJobHandler(c *gin.Context) {
 		chaincodeName = ccname
 	}
 
-	// chaincodeName2 := "bumpy"
-
 	channelName := "mychannel"
 	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
 		channelName = cname
@@ -468,58 +618,40 @@ func TakeJobHandler(c *gin.Context) {
 
 	contract := network.GetContract(chaincodeName)
 
-	var params TakeJobParams
+	var params JobDoneParams
 	if err := c.ShouldBindJSON(&params); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	takeJob(contract, params.JobID)
-	c.IndentedJSON(http.StatusOK, gin.H{"message": "Job added to your general contract."})
+	fmt.Printf("\n--> Submit Transaction: ReleaseJob, function gives back job %s\n", params.JobID)
+	_, err = submitTransaction(contract, "ReleaseJob", params.JobID)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "released job"})
 }
 
-func finishJobCorrectError(contract *client.Contract, jobID string) {
-	fmt.Println("\n--> Submit Transaction: Finish job correct error, function updates a key value pair on the ledger \n")
+// Evaluate a transaction by key to query ledger state.
+func ReadGC(contract *client.Contract) *GeneralContract {
+	fmt.Printf("\n--> Evaluate Transaction: Read, function returns key value pair\n")
 
-	submitResult, err := contract.SubmitTransaction("JobDoneCorrectError", jobID)
+	evaluateResult, err := contract.EvaluateTransaction("ReadGeneralContract", technichianID)
 	if err != nil {
-		switch err := err.(type) {
-		case *client.EndorseError:
-			fmt.Printf("Endorse error for transaction %s with gRPC status %v: %s\n", err.TransactionID, status.Code(err), err)
-		case *client.SubmitError:
-			fmt.Printf("Submit error for transaction %s with gRPC status %v: %s\n", err.TransactionID, status.Code(err), err)
-		case *client.CommitStatusError:
-			if errors.Is(err, context.DeadlineExceeded) {
-				fmt.Printf("Timeout waiting for transaction %s commit status: %s", err.TransactionID, err)
-			} else {
-				fmt.Printf("Error obtaining commit status for transaction %s with gRPC status %v: %s\n", err.TransactionID, status.Code(err), err)
-			}
-		case *client.CommitError:
-			fmt.Printf("Transaction %s failed to commit with status %d: %s\n", err.TransactionID, int32(err.Code), err)
-		default:
-			panic(fmt.Errorf("unexpected error type %T: %w", err, err))
-		}
-
-		// Any error that originates from a peer or orderer node external to the gateway will have its details
-		// embedded within the gRPC status error. The following code shows how to extract that.
-		statusErr := status.Convert(err)
-
-		details := statusErr.Details()
-		if len(details) > 0 {
-			fmt.Println("Error Details:")
-
-			for _, detail := range details {
-				switch detail := detail.(type) {
-				case *gateway.ErrorDetail:
-					fmt.Printf("- address: %s, mspId: %s, message: %s\n", detail.Address, detail.MspId, detail.Message)
-				}
-			}
-		}
+		panic(fmt.Errorf("failed to evaluate transaction: %w", err))
+	}
+	var gc GeneralContract
+	err = json.Unmarshal(evaluateResult, &gc)
+	if err != nil {
+		panic(fmt.Errorf("failed to unmarshal result: %w", err))
 	}
 
-	fmt.Println("Result:", submitResult)
+	fmt.Println("Result: ", gc)
+
+	return &gc
 }
 
-func FinishJobCorrectErrorHandler(c *gin.Context) {
+func ReadGCHandler(c *gin.Context) {
 	clientConnection := newGrpcConnection()
 	defer clientConnection.Close()
 
@@ -562,59 +694,52 @@ func FinishJobCorrectErrorHandler(c *gin.Context) {
 	network := gw.GetNetwork(channelName)
 
 	contract := network.GetContract(chaincodeName)
+	readResult := ReadGC(contract)
+	c.IndentedJSON(http.StatusOK, readResult)
+}
 
-	var params JobDoneParams
-	if err := c.ShouldBindJSON(&params); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
-		return
+func readJob(contract *client.Contract, jobID string) {
+	fmt.Printf("\n--> Evaluate Transaction: Read, function returns key value pair\n")
+
+	evaluateResult, err := contract.EvaluateTransaction("ReadJob", jobID)
+	if err != nil {
+		panic(fmt.Errorf("failed to evaluate transaction: %w", err))
 	}
-	finishJobCorrectError(contract, params.JobID)
-	c.IndentedJSON(http.StatusOK, gin.H{"message": "finished job with correct error"})
+
+	fmt.Println("Result: ", string(evaluateResult[:]))
 }
 
-func finishJobWrongError(contract *client.Contract, jobID string) {
-	fmt.Println("\n--> Submit Transaction: FinishJob wrong error, function updates a key value pair on the ledger \n")
+func getAllJobs(contract *client.Contract) ([]byte, error) {
+	fmt.Printf("\n--> Evaluate Transaction: Read, function returns key value pair\n")
 
-	submitResult, err := contract.SubmitTransaction("JobDoneWrongError", jobID)
+	evaluateResult, err := contract.EvaluateTransaction("GetAllJobs")
 	if err != nil {
-		switch err := err.(type) {
-		case *client.EndorseError:
-			fmt.Printf("Endorse error for transaction %s with gRPC status %v: %s\n", err.TransactionID, status.Code(err), err)
-		case *client.SubmitError:
-			fmt.Printf("Submit error for transaction %s with gRPC status %v: %s\n", err.TransactionID, status.Code(err), err)
-		case *client.CommitStatusError:
-			if errors.Is(err, context.DeadlineExceeded) {
-				fmt.Printf("Timeout waiting for transaction %s commit status: %s", err.TransactionID, err)
-			} else {
-				fmt.Printf("Error obtaining commit status for transaction %s with gRPC status %v: %s\n", err.TransactionID, status.Code(err), err)
-			}
-		case *client.CommitError:
-			fmt.Printf("Transaction %s failed to commit with status %d: %s\n", err.TransactionID, int32(err.Code), err)
-		default:
-			panic(fmt.Errorf("unexpected error type %T: %w", err, err))
-		}
+		return nil, err
+	}
 
-		// Any error that originates from a peer or orderer node external to the gateway will have its details
-		// embedded within the gRPC status error. The following code shows how to extract that.
-		statusErr := status.Convert(err)
+	fmt.Println("Result: ", string(evaluateResult[:]))
 
-		details := statusErr.Details()
-		if len(details) > 0 {
-			fmt.Println("Error Details:")
+	return evaluateResult, nil
+}
 
-			for _, detail := range details {
-				switch detail := detail.(type) {
-				case *gateway.ErrorDetail:
-					fmt.Printf("- address: %s, mspId: %s, message: %s\n", detail.Address, detail.MspId, detail.Message)
-				}
-			}
-		}
+func getJobsPage(contract *client.Contract, pageSize string, bookmark string) (*JobPage, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetJobsPage, function returns one page of jobs\n")
+
+	evaluateResult, err := contract.EvaluateTransaction("GetJobsPage", technichianID, pageSize, bookmark)
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Println("Result:", submitResult)
+	var page JobPage
+	err = json.Unmarshal(evaluateResult, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
 }
 
-func FinishJobWrongErrorHandler(c *gin.Context) {
+func GetAllJobsHandler(c *gin.Context) {
 	clientConnection := newGrpcConnection()
 	defer clientConnection.Close()
 
@@ -657,42 +782,45 @@ func FinishJobWrongErrorHandler(c *gin.Context) {
 	network := gw.GetNetwork(channelName)
 
 	contract := network.GetContract(chaincodeName)
-	var params JobDoneParams
-	if err := c.ShouldBindJSON(&params); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+	if pageSize := c.Query("pageSize"); pageSize != "" {
+		page, err := getJobsPage(contract, pageSize, c.Query("bookmark"))
+		if err != nil {
+			c.IndentedJSON(400, "Couldn't get jobs")
+			return
+		}
+		c.IndentedJSON(http.StatusOK, page)
+		return
+	}
+	result, err := getAllJobs(contract)
+	if err != nil {
+		c.IndentedJSON(400, "Couln't get all jobs")
 		return
 	}
-	finishJobWrongError(contract, params.JobID)
-	c.IndentedJSON(http.StatusOK, gin.H{"message": "finished job with wrong error"})
+	c.IndentedJSON(http.StatusOK, result)
 }
 
-// Evaluate a transaction by key to query ledger state.
-func ReadGC(contract *client.Contract) *GeneralContract {
-	fmt.Printf("\n--> Evaluate Transaction: Read, function returns key value pair\n")
+func listJobTypes(contract *client.Contract) ([]JobType, error) {
+	fmt.Printf("\n--> Evaluate Transaction: ListJobTypes, function returns the job type registry\n")
 
-	evaluateResult, err := contract.EvaluateTransaction("ReadGeneralContract", technichianID)
+	evaluateResult, err := contract.EvaluateTransaction("ListJobTypes")
 	if err != nil {
-		panic(fmt.Errorf("failed to evaluate transaction: %w", err))
+		return nil, err
 	}
-	var gc GeneralContract
-	err = json.Unmarshal(evaluateResult, &gc)
+
+	var jobTypes []JobType
+	err = json.Unmarshal(evaluateResult, &jobTypes)
 	if err != nil {
-		panic(fmt.Errorf("failed to unmarshal result: %w", err))
+		return nil, err
 	}
 
-	fmt.Println("Result: ", gc)
-
-	return &gc
+	return jobTypes, nil
 }
 
-func ReadGCHandler(c *gin.Context) {
+func ListJobTypesHandler(c *gin.Context) {
 	clientConnection := newGrpcConnection()
 	defer clientConnection.Close()
 
 	id := newIdentity()
-	id1 := id.Credentials()
-	fmt.Println("id1: ", string(id1[:]))
-	fmt.Println("mspID: ", id.MspID())
 	sign := newSign()
 
 	// Create a Gateway connection for a specific client identity
@@ -718,8 +846,6 @@ func ReadGCHandler(c *gin.Context) {
 		chaincodeName = ccname
 	}
 
-	// chaincodeName2 := "bumpy"
-
 	channelName := "mychannel"
 	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
 		channelName = cname
@@ -728,42 +854,38 @@ func ReadGCHandler(c *gin.Context) {
 	network := gw.GetNetwork(channelName)
 
 	contract := network.GetContract(chaincodeName)
-	readResult := ReadGC(contract)
-	c.IndentedJSON(http.StatusOK, readResult)
-}
-
-func readJob(contract *client.Contract, jobID string) {
-	fmt.Printf("\n--> Evaluate Transaction: Read, function returns key value pair\n")
-
-	evaluateResult, err := contract.EvaluateTransaction("ReadJob", jobID)
+	jobTypes, err := listJobTypes(contract)
 	if err != nil {
-		panic(fmt.Errorf("failed to evaluate transaction: %w", err))
+		c.IndentedJSON(400, "Couldn't list job types")
+		return
 	}
-
-	fmt.Println("Result: ", string(evaluateResult[:]))
+	c.IndentedJSON(http.StatusOK, jobTypes)
 }
 
-func getAllJobs(contract *client.Contract) ([]byte, error) {
-	fmt.Printf("\n--> Evaluate Transaction: Read, function returns key value pair\n")
+func getRates(contract *client.Contract) (*Rates, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetRates, function returns the current and upcoming rates of a service\n")
 
-	evaluateResult, err := contract.EvaluateTransaction("GetAllJobs")
+	evaluateResult, err := contract.EvaluateTransaction("GetRates")
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println("Result: ", string(evaluateResult[:]))
+	var rates Rates
+	err = json.Unmarshal(evaluateResult, &rates)
+	if err != nil {
+		return nil, err
+	}
 
-	return evaluateResult, nil
+	return &rates, nil
 }
 
-func GetAllJobsHandler(c *gin.Context) {
+// GetRatesHandler returns the rates of the service chaincode named in the path, so that the
+// technician can see what a job pays before taking it.
+func GetRatesHandler(c *gin.Context) {
 	clientConnection := newGrpcConnection()
 	defer clientConnection.Close()
 
 	id := newIdentity()
-	id1 := id.Credentials()
-	fmt.Println("id1: ", string(id1[:]))
-	fmt.Println("mspID: ", id.MspID())
 	sign := newSign()
 
 	// Create a Gateway connection for a specific client identity
@@ -783,14 +905,6 @@ func GetAllJobsHandler(c *gin.Context) {
 
 	defer gw.Close()
 
-	// Override default values for chaincode and channel name as they may differ in testing contexts.
-	chaincodeName := "gc"
-	if ccname := os.Getenv("CHAINCODE_NAME"); ccname != "" {
-		chaincodeName = ccname
-	}
-
-	// chaincodeName2 := "bumpy"
-
 	channelName := "mychannel"
 	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
 		channelName = cname
@@ -798,12 +912,13 @@ func GetAllJobsHandler(c *gin.Context) {
 
 	network := gw.GetNetwork(channelName)
 
-	contract := network.GetContract(chaincodeName)
-	result, err := getAllJobs(contract)
+	contract := network.GetContract(c.Param("service"))
+	rates, err := getRates(contract)
 	if err != nil {
-		c.IndentedJSON(400, "Couln't get all jobs")
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
 	}
-	c.IndentedJSON(http.StatusOK, result)
+	c.IndentedJSON(http.StatusOK, rates)
 }
 
 // Submit transaction, passing in the wrong number of arguments ,expected to throw an error containing details of any error responses from the smart contract.
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/nalle631/arrowheadfunctions"
+)
+
+// WorkOrder is the work order of the external system, it is what an oracle attests to when a job is taken.
+type WorkOrder struct {
+	OracleID  string    `json:"oracleId"`
+	WorkID    string    `json:"workId"`
+	ProductID string    `json:"productId"`
+	EventType string    `json:"eventType"`
+	Address   string    `json:"address"`
+	StartTime time.Time `json:"startTime"`
+	// SLAID is the customer SLA the work order is done under. ServiceLevel is only set
+	// when the external system attests the level of the SLA itself.
+	SLAID        string `json:"slaId"`
+	ServiceLevel string `json:"serviceLevel,omitempty"`
+}
+
+// SignedWorkOrder is the attestation and signature passed to TakeJob.
+type SignedWorkOrder struct {
+	Attestation string `json:"attestation"`
+	Signature   string `json:"signature"`
+}
+
+type OffLedgerRequest struct {
+	WorkID   string `json:"workId"`
+	WorkerID string `json:"workerId"`
+}
+
+// getSignedWorkOrder fetches the work order for jobID from the external system. If the external system
+// does not sign its work orders, the key in ORACLE_KEY_PATH is used as a local stand-in oracle.
+func getSignedWorkOrder(jobID string) (*SignedWorkOrder, error) {
+	body, err := fetchWorkOrder(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var signedWorkOrder SignedWorkOrder
+	err = json.Unmarshal(body, &signedWorkOrder)
+	if err == nil && signedWorkOrder.Attestation != "" && signedWorkOrder.Signature != "" {
+		return &signedWorkOrder, nil
+	}
+
+	oracleKeyPath := os.Getenv("ORACLE_KEY_PATH")
+	if oracleKeyPath == "" {
+		return nil, fmt.Errorf("work order %s is not signed and ORACLE_KEY_PATH is not set", jobID)
+	}
+	oracleID := os.Getenv("ORACLE_ID")
+	if oracleID == "" {
+		oracleID = "local"
+	}
+
+	return signWorkOrder(body, oracleID, oracleKeyPath)
+}
+
+// fetchWorkOrder asks the external system for the work order of jobID. WORKORDERURL can be set to
+// call a work order service directly instead of looking it up through the Arrowhead orchestrator.
+func fetchWorkOrder(jobID string) ([]byte, error) {
+	offLedgerRequest := OffLedgerRequest{
+		WorkID:   jobID,
+		WorkerID: technichianID,
+	}
+	marshalledRequest, err := json.Marshal(offLedgerRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	var serviceResp *http.Response
+	if workOrderURL := os.Getenv("WORKORDERURL"); workOrderURL != "" {
+		serviceResp, err = http.Post(workOrderURL, "application/json", bytes.NewReader(marshalledRequest))
+	} else {
+		serviceResp, err = orchestrateWorkOrder(marshalledRequest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer serviceResp.Body.Close()
+
+	if serviceResp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("job %s does not exist in external system", jobID)
+	}
+	if serviceResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("external system answered %s for job %s", serviceResp.Status, jobID)
+	}
+
+	body, err := io.ReadAll(serviceResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("work order: ", string(body))
+
+	return body, nil
+}
+
+func orchestrateWorkOrder(marshalledRequest []byte) (*http.Response, error) {
+	orchestratorIP := "127.0.0.1"
+	if address := os.Getenv("ORCHESTRATORADDRESS"); address != "" {
+		orchestratorIP = address
+	}
+	orchestratorPort := 8441
+	if port := os.Getenv("ORCHESTRATORPORT"); port != "" {
+		var err error
+		orchestratorPort, err = strconv.Atoi(port)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var orchSystem arrowheadfunctions.System
+	orchSystem.Address = os.Getenv("SYSTEMADDRESS")
+	orchSystem.AuthenticationInfo = ""
+	orchSystem.Port, _ = strconv.Atoi(os.Getenv("SYSTEMPORT"))
+	orchSystem.SystemName = os.Getenv("SYSTEMNAME")
+
+	var orchBody arrowheadfunctions.Orchestrate
+	orchBody.OrchestrationFlags.EnableInterCloud = false
+	orchBody.OrchestrationFlags.OverrideStore = false
+	orchBody.RequestedService.InterfaceRequirements = []string{"HTTP-SECURE-JSON"}
+	orchBody.RequestedService.ServiceDefinitionRequirement = "assign-worker"
+	orchBody.RequesterSystem = orchSystem
+
+	orchResponseJSON := arrowheadfunctions.Orchestration(orchBody, orchestratorIP, orchestratorPort, arrowheadCert, arrowheadKey, arrowheadTruststore)
+	var orchResponse arrowheadfunctions.OrchResponse
+	err := json.Unmarshal(orchResponseJSON, &orchResponse)
+	if err != nil {
+		return nil, err
+	}
+	if len(orchResponse.Response) == 0 {
+		return nil, fmt.Errorf("no provider of assign-worker found by the orchestrator")
+	}
+	choosenSystem := orchResponse.Response[0]
+	fmt.Println("Choosen system: ", choosenSystem)
+
+	req, err := http.NewRequest("POST", "https://"+choosenSystem.Provider.Address+":"+strconv.Itoa(choosenSystem.Provider.Port)+choosenSystem.ServiceUri, bytes.NewReader(marshalledRequest))
+	if err != nil {
+		return nil, err
+	}
+
+	client := arrowheadfunctions.GetClient(arrowheadCert, arrowheadKey, arrowheadTruststore)
+	return client.Do(req)
+}
+
+// signWorkOrder attests an unsigned work order with the ECDSA key in keyPath, the same way an oracle does.
+func signWorkOrder(body []byte, oracleID string, keyPath string) (*SignedWorkOrder, error) {
+	var workOrder WorkOrder
+	err := json.Unmarshal(body, &workOrder)
+	if err != nil {
+		return nil, err
+	}
+	workOrder.OracleID = oracleID
+
+	attestation, err := json.Marshal(workOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := loadOracleKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(attestation)
+	signature, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedWorkOrder{
+		Attestation: string(attestation),
+		Signature:   base64.StdEncoding.EncodeToString(signature),
+	}, nil
+}
+
+func loadOracleKey(keyPath string) (*ecdsa.PrivateKey, error) {
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read oracle key file: %w", err)
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("oracle key is not PEM encoded")
+	}
+
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oracle key: %w", err)
+	}
+	privateKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("oracle key must be an ECDSA key")
+	}
+	return privateKey, nil
+}
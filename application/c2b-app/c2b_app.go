@@ -43,7 +43,12 @@ type CustomerParams struct {
 	CustomerID string `json:"CustomerID"`
 }
 
+// CreateSLAParams has an optional QuoteID of a quote from POST /quote to buy the SLA at the quoted price,
+// and an optional RenewalPolicy, AutoRenew or Manual, that defaults to AutoRenew.
 type CreateSLAParams struct {
+	MowerSerial       string  `json:"MowerSerial"`
+	QuoteID           string  `json:"QuoteID,omitempty"`
+	RenewalPolicy     string  `json:"RenewalPolicy,omitempty"`
 	ServiceLevel      string  `json:"ServiceLevel"`
 	TargetGrassLength float32 `json:"TargetGrassLength"`
 	MaxGrassLength    float32 `json:"MaxGrassLength"`
@@ -93,7 +98,53 @@ type UpdateSlaParams struct {
 type SLA struct {
 	AppraisedValue int `json:"AppraisedValue,omitempty"`
 	SlaParams
-	ID string `json:"ID"`
+	ID                   string     `json:"ID"`
+	MowerSerial          string     `json:"MowerSerial,omitempty"`
+	QuoteID              string     `json:"QuoteID,omitempty"`
+	PricingPolicyVersion int        `json:"PricingPolicyVersion"`
+	StartDate            time.Time  `json:"StartDate"`
+	EndDate              time.Time  `json:"EndDate"`
+	RenewalPolicy        string     `json:"RenewalPolicy"`
+	Status               string     `json:"Status"`
+	SuspendedAt          *time.Time `json:"SuspendedAt,omitempty"`
+	CancellationDate     *time.Time `json:"CancellationDate,omitempty"`
+	TerminatedAt         *time.Time `json:"TerminatedAt,omitempty"`
+}
+
+type PricingPolicy struct {
+	Version       int       `json:"Version"`
+	Formula       string    `json:"Formula"`
+	BaseCosts     BaseCosts `json:"BaseCosts"`
+	SpreadWeight  float32   `json:"SpreadWeight"`
+	TargetWeight  float32   `json:"TargetWeight"`
+	EffectiveFrom time.Time `json:"EffectiveFrom"`
+	SetAt         time.Time `json:"SetAt"`
+	SetBy         string    `json:"SetBy"`
+}
+
+type BaseCosts struct {
+	Standard float32 `json:"Standard"`
+	Gold     float32 `json:"Gold"`
+	Platinum float32 `json:"Platinum"`
+}
+
+// SLAEvaluation is the monthly cost of an SLA and the pricing policy that produced it.
+type SLAEvaluation struct {
+	MonthlyCost   int            `json:"MonthlyCost"`
+	PricingPolicy *PricingPolicy `json:"PricingPolicy"`
+}
+
+type SLAVersion struct {
+	TxID      string    `json:"TxID"`
+	Timestamp time.Time `json:"Timestamp"`
+	IsDelete  bool      `json:"IsDelete"`
+	SLA       *SLA      `json:"SLA,omitempty"`
+}
+
+type SLAPage struct {
+	SLAs                []SLA  `json:"SLAs"`
+	Bookmark            string `json:"Bookmark"`
+	FetchedRecordsCount int32  `json:"FetchedRecordsCount"`
 }
 
 func main() {
@@ -187,8 +238,10 @@ func CreateRouter() *gin.Engine {
 	r := gin.Default()
 
 	r.GET("/contract/:id", ReadCustomerHandler)
+	r.GET("/sla", ListSLAsHandler)
 	r.GET("/sla/:id", ReadSLAHandler)
 	r.GET("/sla/:id/servicelevel", GetServiceLevelHandler)
+	r.GET("/sla/:id/history", GetSLAHistoryHandler)
 	r.POST("/contract", CreateCustomerHandler)
 	r.POST(":customer_id/sla", CreateSLAHandler)
 	r.PUT(":customer_id/sla/:id", updateSLAHandler)
@@ -196,6 +249,19 @@ func CreateRouter() *gin.Engine {
 	r.PUT("/sla/:id/intervall", updateGrassLengthIntervalHandler)
 	r.PUT("sla/:id/servicelevel", updateServiceLevelHandler)
 	r.POST("/sla/evaluate", evaluateSLAHandler)
+	r.POST("/quote", CreateQuoteHandler)
+	r.GET("/quote/:id", ReadQuoteHandler)
+	r.POST("/mower", RegisterMowerHandler)
+	r.GET("/mower/:serial", ReadMowerHandler)
+	r.PUT("/mower/:serial/transfer", TransferMowerHandler)
+	r.POST("/mower/:serial/decommission", DecommissionMowerHandler)
+	r.GET("/contract/:id/mowers", GetCustomerMowersHandler)
+	r.GET("/contract/:id/invoices", GetInvoicesHandler)
+	r.POST("/sla/:id/suspend", SLALifecycleHandler("SuspendSLA"))
+	r.POST("/sla/:id/resume", SLALifecycleHandler("ResumeSLA"))
+	r.POST("/sla/:id/renew", SLALifecycleHandler("RenewSLA"))
+	r.POST("/sla/:id/cancel", SLALifecycleHandler("CancelSLA"))
+	r.POST("/sla/:id/settle", SLALifecycleHandler("SettleSLA"))
 	r.DELETE("/sla/:id", removeSLAHandler)
 	return r
 }
@@ -291,7 +357,7 @@ func CreateCustomerHandler(c *gin.Context) {
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "Customer created successfully"})
 }
 
-func createSLA(contract *client.Contract, customerID string, serviceLevel string, targetgrasslength float32, maxgrasslength float32, mingrasslength float32) (*SLA, error) {
+func createSLA(contract *client.Contract, customerID string, mowerSerial string, quoteID string, renewalPolicy string, serviceLevel string, targetgrasslength float32, maxgrasslength float32, mingrasslength float32) (*SLA, error) {
 	fmt.Println("\n--> Submit Transaction: createSLA")
 	newUUID := uuid.New()
 	newUUIDString := newUUID.String()
@@ -301,7 +367,7 @@ func createSLA(contract *client.Contract, customerID string, serviceLevel string
 	maxgrasslength_string := fmt.Sprintf("%f", maxgrasslength)
 	mingrasslength_string := fmt.Sprintf("%f", mingrasslength)
 
-	createResult, err := contract.SubmitTransaction("CreateSLA", customerID, newUUIDString, serviceLevel, targetgrasslength_string, maxgrasslength_string, mingrasslength_string)
+	createResult, err := contract.SubmitTransaction("CreateSLA", customerID, newUUIDString, mowerSerial, quoteID, renewalPolicy, serviceLevel, targetgrasslength_string, maxgrasslength_string, mingrasslength_string)
 
 	if err != nil {
 		switch err := err.(type) {
@@ -391,10 +457,10 @@ func CreateSLAHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	sla, err := createSLA(contract, customerID, slaParams.ServiceLevel, slaParams.TargetGrassLength, slaParams.MaxGrassLength, slaParams.MinGrassLength)
+	sla, err := createSLA(contract, customerID, slaParams.MowerSerial, slaParams.QuoteID, slaParams.RenewalPolicy, slaParams.ServiceLevel, slaParams.TargetGrassLength, slaParams.MaxGrassLength, slaParams.MinGrassLength)
 
 	if err != nil {
-		c.JSON(501, gin.H{"error": err.Error()})
+		transactionErrorResponse(c, 501, err)
 		return
 	}
 	c.IndentedJSON(http.StatusOK, sla.ID)
@@ -448,11 +514,19 @@ func updateSLAHandler(c *gin.Context) {
 		return
 	}
 
-	updateTargetGrassLength(contract, customerID, slaID, slaParams.TargetGrassLength)
-	updateGrassLengthInterval(contract, customerID, slaID, slaParams.MaxGrassLength, slaParams.MinGrassLength)
+	err = updateTargetGrassLength(contract, customerID, slaID, slaParams.TargetGrassLength)
+	if err != nil {
+		transactionErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	err = updateGrassLengthInterval(contract, customerID, slaID, slaParams.MaxGrassLength, slaParams.MinGrassLength)
+	if err != nil {
+		transactionErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
 	err = updateServiceLevel(contract, customerID, slaID, slaParams.ServiceLevel)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		transactionErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 	c.IndentedJSON(http.StatusOK, gin.H{"status": "ok"})
@@ -550,15 +624,15 @@ func updateServiceLevelHandler(c *gin.Context) {
 	}
 	err = updateServiceLevel(contract, updateServiceLevelParams.CustomerID, slaID, updateServiceLevelParams.ServiceLevel)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		transactionErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "Service level updated successfully"})
 }
 
 // Submit a transaction to query ledger state.
-func updateTargetGrassLength(contract *client.Contract, customerID string, slaID string, targetgrasslength float32) {
-	fmt.Println("\n--> Submit Transaction: updateTargetGrassLength \n")
+func updateTargetGrassLength(contract *client.Contract, customerID string, slaID string, targetgrasslength float32) error {
+	fmt.Printf("\n--> Submit Transaction: updateTargetGrassLength\n")
 	fmt.Println(targetgrasslength)
 	targetgrasslength_string := fmt.Sprintf("%f", targetgrasslength)
 	fmt.Println(targetgrasslength_string)
@@ -598,9 +672,11 @@ func updateTargetGrassLength(contract *client.Contract, customerID string, slaID
 				}
 			}
 		}
+		return err
 	}
 
 	fmt.Println("Result:", submitResult)
+	return nil
 }
 
 func updateTargetGrassLengthHandler(c *gin.Context) {
@@ -649,12 +725,16 @@ func updateTargetGrassLengthHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	updateTargetGrassLength(contract, updateTargetGrassLengthParams.CustomerID, slaID, updateTargetGrassLengthParams.TargetGrassLength)
+	err = updateTargetGrassLength(contract, updateTargetGrassLengthParams.CustomerID, slaID, updateTargetGrassLengthParams.TargetGrassLength)
+	if err != nil {
+		transactionErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "TargetGrassLength updated successfully"})
 }
 
-func updateGrassLengthInterval(contract *client.Contract, customerID string, slaID string, maxgrasslength float32, mingrasslength float32) {
-	fmt.Println("\n--> Submit Transaction: updateGrassLengthInterval \n")
+func updateGrassLengthInterval(contract *client.Contract, customerID string, slaID string, maxgrasslength float32, mingrasslength float32) error {
+	fmt.Printf("\n--> Submit Transaction: updateGrassLengthInterval\n")
 
 	maxgrasslength_string := fmt.Sprintf("%f", maxgrasslength)
 	mingrasslength_string := fmt.Sprintf("%f", mingrasslength)
@@ -692,9 +772,11 @@ func updateGrassLengthInterval(contract *client.Contract, customerID string, sla
 				}
 			}
 		}
+		return err
 	}
 
 	fmt.Println("Result:", submitResult)
+	return nil
 }
 
 func updateGrassLengthIntervalHandler(c *gin.Context) {
@@ -743,12 +825,16 @@ func updateGrassLengthIntervalHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	updateGrassLengthInterval(contract, updateGrassLengthIntervalParams.CustomerID, slaID, updateGrassLengthIntervalParams.MaxGrassLength, updateGrassLengthIntervalParams.MinGrassLength)
+	err = updateGrassLengthInterval(contract, updateGrassLengthIntervalParams.CustomerID, slaID, updateGrassLengthIntervalParams.MaxGrassLength, updateGrassLengthIntervalParams.MinGrassLength)
+	if err != nil {
+		transactionErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "GrassLengthInterval updated successfully"})
 }
 
 func removeSLA(contract *client.Contract, customerID string, slaID string) {
-	fmt.Println("\n--> Submit Transaction: updateGrassLengthInterval \n")
+	fmt.Printf("\n--> Submit Transaction: updateGrassLengthInterval\n")
 
 	submitResult, err := contract.SubmitTransaction("RemoveSLA", customerID, slaID)
 	if err != nil {
@@ -838,7 +924,7 @@ func removeSLAHandler(c *gin.Context) {
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "SLA removed successfully"})
 }
 
-func evaluateSLA(contract *client.Contract, sla SlaParams) (int, error) {
+func evaluateSLA(contract *client.Contract, sla SlaParams) (*SLAEvaluation, error) {
 	fmt.Printf("\n--> Evaluate Transaction: EvaluateSLA, function returns evaluation of an SLA\n")
 	maxgrasslength_string := fmt.Sprintf("%f", sla.MaxGrassLength)
 	mingrasslength_string := fmt.Sprintf("%f", sla.MinGrassLength)
@@ -877,11 +963,15 @@ func evaluateSLA(contract *client.Contract, sla SlaParams) (int, error) {
 				}
 			}
 		}
-		return 0, err
+		return nil, err
 	}
-	evaluateResult_string := string(evaluateResult[:])
 
-	return strconv.Atoi(evaluateResult_string)
+	var evaluation SLAEvaluation
+	err = json.Unmarshal(evaluateResult, &evaluation)
+	if err != nil {
+		return nil, err
+	}
+	return &evaluation, nil
 }
 
 func evaluateSLAHandler(c *gin.Context) {
@@ -937,7 +1027,7 @@ func evaluateSLAHandler(c *gin.Context) {
 	fmt.Println("Json recieved: ", slaParams)
 	evaluatedValue, err := evaluateSLA(contract, slaParams)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		transactionErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 	c.IndentedJSON(http.StatusOK, evaluatedValue)
@@ -1090,6 +1180,150 @@ func GetServiceLevelHandler(c *gin.Context) {
 	c.Data(200, "text/plain; charset=utf8", []byte(sla.ServiceLevel))
 }
 
+func listSLAs(contract *client.Contract, pageSize int32, bookmark string, serviceLevel string, minPrice int, maxPrice int) (*SLAPage, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetSLAsPage, function returns a page of SLAs\n")
+
+	evaluateResult, err := contract.EvaluateTransaction("GetSLAsPage", strconv.Itoa(int(pageSize)), bookmark, serviceLevel, strconv.Itoa(minPrice), strconv.Itoa(maxPrice))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	var page SLAPage
+	err = json.Unmarshal(evaluateResult, &page)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Result: ", string(evaluateResult[:]))
+	return &page, nil
+}
+
+// ListSLAsHandler returns a page of SLAs. The query parameters are pageSize (20 by default), bookmark,
+// from the previous page, and the optional filters serviceLevel, minPrice and maxPrice.
+func ListSLAsHandler(c *gin.Context) {
+	pageSize, err := strconv.ParseInt(c.DefaultQuery("pageSize", "20"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pageSize"})
+		return
+	}
+	minPrice, err := strconv.Atoi(c.DefaultQuery("minPrice", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid minPrice"})
+		return
+	}
+	maxPrice, err := strconv.Atoi(c.DefaultQuery("maxPrice", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid maxPrice"})
+		return
+	}
+
+	clientConnection := newGrpcConnection()
+	defer clientConnection.Close()
+
+	id := newIdentity()
+	sign := newSign()
+
+	// Create a Gateway connection for a specific client identity
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithClientConnection(clientConnection),
+		// Default timeouts for different gRPC calls
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	defer gw.Close()
+
+	// Override default values for chaincode and channel name as they may differ in testing contexts.
+	chaincodeName := "mower"
+	if ccname := os.Getenv("CHAINCODE_NAME"); ccname != "" {
+		chaincodeName = ccname
+	}
+
+	channelName := "customer"
+	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
+		channelName = cname
+	}
+
+	network := gw.GetNetwork(channelName)
+
+	contract := network.GetContract(chaincodeName)
+	page, err := listSLAs(contract, int32(pageSize), c.Query("bookmark"), c.Query("serviceLevel"), minPrice, maxPrice)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, page)
+}
+
+func getSLAHistory(contract *client.Contract, slaID string) ([]SLAVersion, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetSLAHistory, function returns every version of an SLA\n")
+
+	evaluateResult, err := contract.EvaluateTransaction("GetSLAHistory", slaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	var versions []SLAVersion
+	err = json.Unmarshal(evaluateResult, &versions)
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetSLAHistoryHandler returns every version of an SLA, oldest first, with the transaction that made it.
+func GetSLAHistoryHandler(c *gin.Context) {
+	clientConnection := newGrpcConnection()
+	defer clientConnection.Close()
+
+	id := newIdentity()
+	sign := newSign()
+
+	// Create a Gateway connection for a specific client identity
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithClientConnection(clientConnection),
+		// Default timeouts for different gRPC calls
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	defer gw.Close()
+
+	// Override default values for chaincode and channel name as they may differ in testing contexts.
+	chaincodeName := "mower"
+	if ccname := os.Getenv("CHAINCODE_NAME"); ccname != "" {
+		chaincodeName = ccname
+	}
+
+	channelName := "customer"
+	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
+		channelName = cname
+	}
+
+	network := gw.GetNetwork(channelName)
+
+	contract := network.GetContract(chaincodeName)
+	versions, err := getSLAHistory(contract, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, versions)
+}
+
 // Evaluate a transaction by key to query ledger state.
 func readCustomer(contract *client.Contract, customerID string) (*Customer, error) {
 	fmt.Printf("\n--> Evaluate Transaction: Read, function returns key value pair\n")
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+type Invoice struct {
+	CustomerID  string            `json:"CustomerID"`
+	Period      string            `json:"Period"`
+	PeriodStart time.Time         `json:"PeriodStart"`
+	PeriodEnd   time.Time         `json:"PeriodEnd"`
+	LineItems   []InvoiceLineItem `json:"LineItems"`
+	Total       int               `json:"Total"`
+	IssuedAt    time.Time         `json:"IssuedAt"`
+	DueDate     time.Time         `json:"DueDate"`
+	Status      string            `json:"Status"`
+	PaidAt      *time.Time        `json:"PaidAt,omitempty"`
+}
+
+type InvoiceLineItem struct {
+	SLAID        string    `json:"SLAID"`
+	ServiceLevel string    `json:"ServiceLevel"`
+	MonthlyCost  int       `json:"MonthlyCost"`
+	From         time.Time `json:"From"`
+	To           time.Time `json:"To"`
+	Days         float64   `json:"Days"`
+	Amount       int       `json:"Amount"`
+}
+
+func getInvoices(contract *client.Contract, customerID string) ([]Invoice, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetInvoices, function returns the invoices of a customer\n")
+
+	evaluateResult, err := contract.EvaluateTransaction("GetInvoices", customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var invoices []Invoice
+	err = json.Unmarshal(evaluateResult, &invoices)
+	if err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+// GetInvoicesHandler returns the invoices of a customer, oldest period first. They are read as the owner-admin,
+// who may read the invoices of every customer.
+func GetInvoicesHandler(c *gin.Context) {
+	clientConnection := newOwnerGrpcConnection()
+	defer clientConnection.Close()
+
+	id := newOwnerIdentity()
+	sign := newOwnerSign()
+
+	// Create a Gateway connection for a specific client identity
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithClientConnection(clientConnection),
+		// Default timeouts for different gRPC calls
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	defer gw.Close()
+
+	// Override default values for chaincode and channel name as they may differ in testing contexts.
+	chaincodeName := "customer"
+	if ccname := os.Getenv("CHAINCODE_NAME"); ccname != "" {
+		chaincodeName = ccname
+	}
+
+	channelName := "customer"
+	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
+		channelName = cname
+	}
+
+	network := gw.GetNetwork(channelName)
+	contract := network.GetContract(chaincodeName)
+
+	invoices, err := getInvoices(contract, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, invoices)
+}
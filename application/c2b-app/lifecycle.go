@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// SLALifecycleParams has the CancellationDate, as YYYY-MM-DD, only when an SLA is cancelled.
+type SLALifecycleParams struct {
+	CustomerID       string `json:"CustomerID"`
+	CancellationDate string `json:"CancellationDate,omitempty"`
+}
+
+func changeSLAStatus(contract *client.Contract, function string, params SLALifecycleParams, slaID string) (*SLA, error) {
+	fmt.Printf("\n--> Submit Transaction: %s, function changes the status of SLA %s\n", function, slaID)
+
+	args := []string{params.CustomerID, slaID}
+	if function == "CancelSLA" {
+		args = append(args, params.CancellationDate)
+	}
+	submitResult, err := contract.SubmitTransaction(function, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var sla SLA
+	err = json.Unmarshal(submitResult, &sla)
+	if err != nil {
+		return nil, err
+	}
+	return &sla, nil
+}
+
+// SLALifecycleHandler returns the handler of a lifecycle transaction of the customer chaincode, SuspendSLA,
+// ResumeSLA, RenewSLA, CancelSLA or SettleSLA, which responds with the changed SLA.
+func SLALifecycleHandler(function string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var params SLALifecycleParams
+		if err := c.BindJSON(&params); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		clientConnection := newGrpcConnection()
+		defer clientConnection.Close()
+
+		id := newIdentity()
+		sign := newSign()
+
+		// Create a Gateway connection for a specific client identity
+		gw, err := client.Connect(
+			id,
+			client.WithSign(sign),
+			client.WithClientConnection(clientConnection),
+			// Default timeouts for different gRPC calls
+			client.WithEvaluateTimeout(5*time.Second),
+			client.WithEndorseTimeout(15*time.Second),
+			client.WithSubmitTimeout(5*time.Second),
+			client.WithCommitStatusTimeout(1*time.Minute),
+		)
+		if err != nil {
+			panic(err)
+		}
+
+		defer gw.Close()
+
+		// Override default values for chaincode and channel name as they may differ in testing contexts.
+		chaincodeName := "customer"
+		if ccname := os.Getenv("CHAINCODE_NAME"); ccname != "" {
+			chaincodeName = ccname
+		}
+
+		channelName := "customer"
+		if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
+			channelName = cname
+		}
+
+		network := gw.GetNetwork(channelName)
+		contract := network.GetContract(chaincodeName)
+
+		sla, err := changeSLAStatus(contract, function, params, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.IndentedJSON(http.StatusOK, sla)
+	}
+}
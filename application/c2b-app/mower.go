@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc"
+)
+
+type Mower struct {
+	SerialNumber        string     `json:"SerialNumber"`
+	Model               string     `json:"Model"`
+	CustomerID          string     `json:"CustomerID"`
+	InstallationAddress string     `json:"InstallationAddress"`
+	FirmwareVersion     string     `json:"FirmwareVersion"`
+	WarrantyStart       time.Time  `json:"WarrantyStart"`
+	WarrantyEnd         time.Time  `json:"WarrantyEnd"`
+	Status              string     `json:"Status"`
+	RegisteredAt        time.Time  `json:"RegisteredAt"`
+	DecommissionedAt    *time.Time `json:"DecommissionedAt,omitempty"`
+}
+
+// RegisterMowerParams has the warranty dates as YYYY-MM-DD.
+type RegisterMowerParams struct {
+	SerialNumber        string `json:"SerialNumber"`
+	Model               string `json:"Model"`
+	CustomerID          string `json:"CustomerID"`
+	InstallationAddress string `json:"InstallationAddress"`
+	FirmwareVersion     string `json:"FirmwareVersion"`
+	WarrantyStart       string `json:"WarrantyStart"`
+	WarrantyEnd         string `json:"WarrantyEnd"`
+}
+
+type TransferMowerParams struct {
+	CustomerID          string `json:"CustomerID"`
+	InstallationAddress string `json:"InstallationAddress"`
+}
+
+// registryContract connects to the mower registry as the owner-admin, who registers, transfers and
+// decommissions the mowers. The caller closes the returned gateway and connection.
+func registryContract() (*client.Contract, *client.Gateway, *grpc.ClientConn) {
+	clientConnection := newOwnerGrpcConnection()
+
+	gw, err := client.Connect(
+		newOwnerIdentity(),
+		client.WithSign(newOwnerSign()),
+		client.WithClientConnection(clientConnection),
+		// Default timeouts for different gRPC calls
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		clientConnection.Close()
+		panic(err)
+	}
+
+	// Override default values for chaincode and channel name as they may differ in testing contexts.
+	chaincodeName := "mower-registry"
+	if ccname := os.Getenv("REGISTRY_CHAINCODE_NAME"); ccname != "" {
+		chaincodeName = ccname
+	}
+
+	channelName := "customer"
+	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
+		channelName = cname
+	}
+
+	return gw.GetNetwork(channelName).GetContract(chaincodeName), gw, clientConnection
+}
+
+// mowerResult returns the mower a registry transaction returned, or the error of the transaction.
+func mowerResult(c *gin.Context, result []byte, err error) {
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var mower Mower
+	err = json.Unmarshal(result, &mower)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, mower)
+}
+
+// RegisterMowerHandler registers a mower to a customer, which must already have a customer contract.
+func RegisterMowerHandler(c *gin.Context) {
+	var params RegisterMowerParams
+	if err := c.BindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contract, gw, clientConnection := registryContract()
+	defer clientConnection.Close()
+	defer gw.Close()
+
+	fmt.Printf("\n--> Submit Transaction: RegisterMower, function registers mower %s to %s\n", params.SerialNumber, params.CustomerID)
+	result, err := contract.SubmitTransaction("RegisterMower", params.SerialNumber, params.Model, params.CustomerID, params.InstallationAddress, params.FirmwareVersion, params.WarrantyStart, params.WarrantyEnd)
+	mowerResult(c, result, err)
+}
+
+func ReadMowerHandler(c *gin.Context) {
+	contract, gw, clientConnection := registryContract()
+	defer clientConnection.Close()
+	defer gw.Close()
+
+	fmt.Printf("\n--> Evaluate Transaction: ReadMower, function returns a registered mower\n")
+	result, err := contract.EvaluateTransaction("ReadMower", c.Param("serial"))
+	mowerResult(c, result, err)
+}
+
+// TransferMowerHandler moves a mower to another customer, an empty InstallationAddress keeps the address.
+func TransferMowerHandler(c *gin.Context) {
+	var params TransferMowerParams
+	if err := c.BindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contract, gw, clientConnection := registryContract()
+	defer clientConnection.Close()
+	defer gw.Close()
+
+	fmt.Printf("\n--> Submit Transaction: TransferMower, function transfers mower %s to %s\n", c.Param("serial"), params.CustomerID)
+	result, err := contract.SubmitTransaction("TransferMower", c.Param("serial"), params.CustomerID, params.InstallationAddress)
+	mowerResult(c, result, err)
+}
+
+func DecommissionMowerHandler(c *gin.Context) {
+	contract, gw, clientConnection := registryContract()
+	defer clientConnection.Close()
+	defer gw.Close()
+
+	fmt.Printf("\n--> Submit Transaction: DecommissionMower, function takes mower %s out of service\n", c.Param("serial"))
+	result, err := contract.SubmitTransaction("DecommissionMower", c.Param("serial"))
+	mowerResult(c, result, err)
+}
+
+func GetCustomerMowersHandler(c *gin.Context) {
+	contract, gw, clientConnection := registryContract()
+	defer clientConnection.Close()
+	defer gw.Close()
+
+	fmt.Printf("\n--> Evaluate Transaction: GetMowersByCustomer, function returns the mowers of a customer\n")
+	result, err := contract.EvaluateTransaction("GetMowersByCustomer", c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var mowers []Mower
+	err = json.Unmarshal(result, &mowers)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, mowers)
+}
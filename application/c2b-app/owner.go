@@ -0,0 +1,82 @@
+package main
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// The owner operations, such as the changes to the mower registry, are submitted as the owner-admin of Org2,
+// the organisation that sells the mowers and the SLAs.
+const (
+	ownerMSPID        = "Org2MSP"
+	ownerCryptoPath   = "../../test-network/organizations/peerOrganizations/org2.example.com"
+	ownerCertPath     = ownerCryptoPath + "/users/User1@org2.example.com/msp/signcerts/User1@org2.example.com-cert.pem"
+	ownerKeyPath      = ownerCryptoPath + "/users/User1@org2.example.com/msp/keystore/"
+	ownerTLSCertPath  = ownerCryptoPath + "/peers/peer0.org2.example.com/tls/ca.crt"
+	ownerPeerEndpoint = "localhost:9051"
+	ownerGatewayPeer  = "peer0.org2.example.com"
+)
+
+// newOwnerGrpcConnection creates a gRPC connection to the Gateway server of the owner organisation.
+func newOwnerGrpcConnection() *grpc.ClientConn {
+	certificate, err := loadCertificate(ownerTLSCertPath)
+	if err != nil {
+		panic(err)
+	}
+
+	certPool := x509.NewCertPool()
+	certPool.AddCert(certificate)
+	transportCredentials := credentials.NewClientTLSFromCert(certPool, ownerGatewayPeer)
+
+	connection, err := grpc.Dial(ownerPeerEndpoint, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		panic(fmt.Errorf("failed to create gRPC connection: %w", err))
+	}
+
+	return connection
+}
+
+// newOwnerIdentity creates the owner-admin client identity.
+func newOwnerIdentity() *identity.X509Identity {
+	certificate, err := loadCertificate(ownerCertPath)
+	if err != nil {
+		panic(err)
+	}
+
+	id, err := identity.NewX509Identity(ownerMSPID, certificate)
+	if err != nil {
+		panic(err)
+	}
+
+	return id
+}
+
+// newOwnerSign creates a function that signs with the private key of the owner-admin.
+func newOwnerSign() identity.Sign {
+	files, err := os.ReadDir(ownerKeyPath)
+	if err != nil {
+		panic(fmt.Errorf("failed to read private key directory: %w", err))
+	}
+	privateKeyPEM, err := os.ReadFile(path.Join(ownerKeyPath, files[0].Name()))
+	if err != nil {
+		panic(fmt.Errorf("failed to read private key file: %w", err))
+	}
+
+	privateKey, err := identity.PrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		panic(err)
+	}
+
+	sign, err := identity.NewPrivateKeySign(privateKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return sign
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc"
+)
+
+// Quote is a binding price for an SLA, it can be used once as the QuoteID of POST /:customer_id/sla
+// before ExpiresAt.
+type Quote struct {
+	ID                   string    `json:"ID"`
+	ServiceLevel         string    `json:"ServiceLevel"`
+	TargetGrassLength    float32   `json:"TargetGrassLength"`
+	MaxGrassLength       float32   `json:"MaxGrassLength"`
+	MinGrassLength       float32   `json:"MinGrassLength"`
+	MonthlyCost          int       `json:"MonthlyCost"`
+	PricingPolicyVersion int       `json:"PricingPolicyVersion"`
+	CreatedAt            time.Time `json:"CreatedAt"`
+	ExpiresAt            time.Time `json:"ExpiresAt"`
+	Status               string    `json:"Status"`
+	SLAID                string    `json:"SLAID,omitempty"`
+}
+
+// mowerContract connects to the mower chaincode. The caller closes the returned gateway and connection.
+func mowerContract() (*client.Contract, *client.Gateway, *grpc.ClientConn) {
+	clientConnection := newGrpcConnection()
+
+	gw, err := client.Connect(
+		newIdentity(),
+		client.WithSign(newSign()),
+		client.WithClientConnection(clientConnection),
+		// Default timeouts for different gRPC calls
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		clientConnection.Close()
+		panic(err)
+	}
+
+	// Override default values for chaincode and channel name as they may differ in testing contexts.
+	chaincodeName := "mower"
+	if ccname := os.Getenv("MOWER_CHAINCODE_NAME"); ccname != "" {
+		chaincodeName = ccname
+	}
+
+	channelName := "customer"
+	if cname := os.Getenv("CHANNEL_NAME"); cname != "" {
+		channelName = cname
+	}
+
+	return gw.GetNetwork(channelName).GetContract(chaincodeName), gw, clientConnection
+}
+
+// quoteResult returns the quote a transaction returned, or the error of the transaction.
+func quoteResult(c *gin.Context, result []byte, err error) {
+	if err != nil {
+		transactionErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	var quote Quote
+	err = json.Unmarshal(result, &quote)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, quote)
+}
+
+// CreateQuoteHandler prices an SLA like /sla/evaluate, but stores the price as a quote that the SLA can be
+// bought at until the quote expires.
+func CreateQuoteHandler(c *gin.Context) {
+	var slaParams SlaParams
+	if err := c.BindJSON(&slaParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contract, gw, clientConnection := mowerContract()
+	defer clientConnection.Close()
+	defer gw.Close()
+
+	fmt.Printf("\n--> Submit Transaction: CreateQuote, function stores a binding price for an SLA\n")
+	maxgrasslength_string := fmt.Sprintf("%f", slaParams.MaxGrassLength)
+	mingrasslength_string := fmt.Sprintf("%f", slaParams.MinGrassLength)
+	targetgrasslength_string := fmt.Sprintf("%f", slaParams.TargetGrassLength)
+	result, err := contract.SubmitTransaction("CreateQuote", slaParams.ServiceLevel, targetgrasslength_string, maxgrasslength_string, mingrasslength_string)
+	quoteResult(c, result, err)
+}
+
+func ReadQuoteHandler(c *gin.Context) {
+	contract, gw, clientConnection := mowerContract()
+	defer clientConnection.Close()
+	defer gw.Close()
+
+	fmt.Printf("\n--> Evaluate Transaction: ReadQuote, function returns a quote\n")
+	result, err := contract.EvaluateTransaction("ReadQuote", c.Param("id"))
+	quoteResult(c, result, err)
+}
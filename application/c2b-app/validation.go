@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"google.golang.org/grpc/status"
+)
+
+// validationErrorPrefix starts the message of an SLA validation error of the mower chaincode, the JSON
+// of the field errors follows it.
+const validationErrorPrefix = "invalid SLA parameters: "
+
+// FieldError is why one parameter of an SLA is invalid.
+type FieldError struct {
+	Field   string `json:"Field"`
+	Message string `json:"Message"`
+}
+
+type ValidationError struct {
+	Errors []FieldError `json:"Errors"`
+}
+
+// fieldErrors returns the field errors of a transaction that the mower chaincode rejected because of
+// invalid SLA parameters, and nil for any other error. The validation error is found in the error details
+// of the peers, where the customer chaincode may have wrapped it.
+func fieldErrors(err error) []FieldError {
+	messages := []string{}
+	for _, detail := range status.Convert(err).Details() {
+		if detail, ok := detail.(*gateway.ErrorDetail); ok {
+			messages = append(messages, detail.Message)
+		}
+	}
+	messages = append(messages, err.Error())
+
+	for _, message := range messages {
+		i := strings.Index(message, validationErrorPrefix)
+		if i < 0 {
+			continue
+		}
+		var validationErr ValidationError
+		// the JSON ends the message, anything after it is ignored by the decoder
+		decodeErr := json.NewDecoder(strings.NewReader(message[i+len(validationErrorPrefix):])).Decode(&validationErr)
+		if decodeErr == nil && len(validationErr.Errors) > 0 {
+			return validationErr.Errors
+		}
+	}
+	return nil
+}
+
+// transactionErrorResponse responds 422 with the invalid fields when the SLA parameters were invalid,
+// and with code otherwise.
+func transactionErrorResponse(c *gin.Context, code int, err error) {
+	if errs := fieldErrors(err); errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid SLA parameters", "fields": errs})
+		return
+	}
+	c.JSON(code, gin.H{"error": err.Error()})
+}
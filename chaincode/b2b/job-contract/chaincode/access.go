@@ -0,0 +1,86 @@
+package gc
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// ownerMSPID is the organisation that owns the services and manages the technicians' general contracts.
+	ownerMSPID = "Org2MSP"
+
+	// roleAttribute is the X.509 certificate attribute, set when the identity is registered
+	// with the CA, that decides what a client may do in the job contract.
+	roleAttribute  = "role"
+	roleTechnician = "technician"
+	roleOwnerAdmin = "owner-admin"
+	roleInspector  = "inspector"
+)
+
+// caller is the identity that submitted the current transaction.
+type caller struct {
+	MSPID string
+	ID    string
+	Role  string
+}
+
+func getCaller(ctx contractapi.TransactionContextInterface) (*caller, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	id, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client ID: %v", err)
+	}
+	role, _, err := ctx.GetClientIdentity().GetAttributeValue(roleAttribute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client role: %v", err)
+	}
+
+	return &caller{MSPID: mspID, ID: id, Role: role}, nil
+}
+
+// requireRole returns the caller if its certificate carries one of the given roles.
+// Owner roles are only honoured for identities issued to the owner organisation.
+func requireRole(ctx contractapi.TransactionContextInterface, roles ...string) (*caller, error) {
+	c, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range roles {
+		if c.Role != role {
+			continue
+		}
+		if isOwnerRole(role) && c.MSPID != ownerMSPID {
+			return nil, fmt.Errorf("role %s is only valid for members of %s, caller is %s", role, ownerMSPID, c.MSPID)
+		}
+		return c, nil
+	}
+
+	return nil, fmt.Errorf("caller with role %q is not allowed to perform this operation, requires one of %v", c.Role, roles)
+}
+
+// authorizeRead returns the caller if it may read the general contract of technicianID.
+// Any identity may read its own organisation's contract, owner admins and inspectors may read all of them.
+func authorizeRead(ctx contractapi.TransactionContextInterface, technicianID string) (*caller, error) {
+	c, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.MSPID == technicianID {
+		return c, nil
+	}
+	if c.MSPID == ownerMSPID && isOwnerRole(c.Role) {
+		return c, nil
+	}
+
+	return nil, fmt.Errorf("%s is not allowed to read the general contract of %s", c.MSPID, technicianID)
+}
+
+func isOwnerRole(role string) bool {
+	return role == roleOwnerAdmin || role == roleInspector
+}
@@ -0,0 +1,235 @@
+package gc
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const oracleObjectType = "Oracle"
+
+// WorkOrder is the work order of the external system as attested by an oracle.
+// It is passed to TakeJob as JSON together with the oracle's signature over those exact bytes.
+type WorkOrder struct {
+	OracleID  string    `json:"oracleId"`
+	WorkID    string    `json:"workId"`
+	ProductID string    `json:"productId"`
+	EventType string    `json:"eventType"`
+	Address   string    `json:"address"`
+	StartTime time.Time `json:"startTime"`
+	// SLAID is the customer SLA the work order is done under. ServiceLevel is only set
+	// when the oracle attests the level of the SLA itself.
+	SLAID        string `json:"slaId"`
+	ServiceLevel string `json:"serviceLevel,omitempty"`
+}
+
+// Oracle is a signer trusted to attest work orders from the external system.
+type Oracle struct {
+	ID           string     `json:"ID"`
+	PublicKey    string     `json:"PublicKey"`
+	RegisteredAt time.Time  `json:"RegisteredAt"`
+	Revoked      bool       `json:"Revoked"`
+	RevokedAt    *time.Time `json:"RevokedAt,omitempty"`
+}
+
+// RegisterOracle trusts work order attestations signed by the ECDSA key in publicKeyPEM.
+func (s *SmartContract) RegisterOracle(ctx contractapi.TransactionContextInterface, oracleID string, publicKeyPEM string) (*Oracle, error) {
+	_, err := requireRole(ctx, roleOwnerAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if oracleID == "" {
+		return nil, fmt.Errorf("oracle id must not be empty")
+	}
+	_, err = parseOraclePublicKey(publicKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	oracleJSON, err := getOracleState(ctx, oracleID)
+	if err != nil {
+		return nil, err
+	}
+	if oracleJSON != nil {
+		return nil, fmt.Errorf("oracle %s is already registered", oracleID)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	oracle := Oracle{
+		ID:           oracleID,
+		PublicKey:    publicKeyPEM,
+		RegisteredAt: txTimestamp.AsTime(),
+	}
+	err = putOracle(ctx, &oracle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oracle, nil
+}
+
+// RevokeOracle stops trusting attestations from an oracle. Jobs already taken are not affected.
+func (s *SmartContract) RevokeOracle(ctx contractapi.TransactionContextInterface, oracleID string) (*Oracle, error) {
+	_, err := requireRole(ctx, roleOwnerAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	oracle, err := readOracle(ctx, oracleID)
+	if err != nil {
+		return nil, err
+	}
+	if oracle.Revoked {
+		return nil, fmt.Errorf("oracle %s is already revoked", oracleID)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	revokedAt := txTimestamp.AsTime()
+	oracle.Revoked = true
+	oracle.RevokedAt = &revokedAt
+	err = putOracle(ctx, oracle)
+	if err != nil {
+		return nil, err
+	}
+
+	return oracle, nil
+}
+
+// ListOracles returns every registered oracle, including revoked ones.
+func (s *SmartContract) ListOracles(ctx contractapi.TransactionContextInterface) ([]*Oracle, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(oracleObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	oracles := []*Oracle{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var oracle Oracle
+		err = json.Unmarshal(queryResponse.Value, &oracle)
+		if err != nil {
+			return nil, err
+		}
+		oracles = append(oracles, &oracle)
+	}
+
+	return oracles, nil
+}
+
+// verifyWorkOrder checks that attestation is signed by a registered oracle that has not been revoked
+// and returns the attested work order. signature is a base64 encoded ASN.1 ECDSA signature of the
+// SHA-256 digest of attestation.
+func verifyWorkOrder(ctx contractapi.TransactionContextInterface, attestation string, signature string) (*WorkOrder, error) {
+	var workOrder WorkOrder
+	err := json.Unmarshal([]byte(attestation), &workOrder)
+	if err != nil {
+		return nil, fmt.Errorf("invalid work order attestation: %v", err)
+	}
+	if workOrder.OracleID == "" || workOrder.WorkID == "" || workOrder.EventType == "" {
+		return nil, fmt.Errorf("work order attestation must have an oracleId, workId and eventType")
+	}
+
+	oracle, err := readOracle(ctx, workOrder.OracleID)
+	if err != nil {
+		return nil, err
+	}
+	if oracle.Revoked {
+		return nil, fmt.Errorf("oracle %s has been revoked", oracle.ID)
+	}
+	publicKey, err := parseOraclePublicKey(oracle.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid work order signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(attestation))
+	if !ecdsa.VerifyASN1(publicKey, digest[:], signatureBytes) {
+		return nil, fmt.Errorf("work order %s is not signed by oracle %s", workOrder.WorkID, oracle.ID)
+	}
+
+	return &workOrder, nil
+}
+
+func parseOraclePublicKey(publicKeyPEM string) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("oracle public key is not PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oracle public key: %v", err)
+	}
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("oracle public key must be an ECDSA key")
+	}
+	return publicKey, nil
+}
+
+func readOracle(ctx contractapi.TransactionContextInterface, oracleID string) (*Oracle, error) {
+	oracleJSON, err := getOracleState(ctx, oracleID)
+	if err != nil {
+		return nil, err
+	}
+	if oracleJSON == nil {
+		return nil, fmt.Errorf("unknown oracle %s", oracleID)
+	}
+
+	var oracle Oracle
+	err = json.Unmarshal(oracleJSON, &oracle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oracle, nil
+}
+
+func getOracleState(ctx contractapi.TransactionContextInterface, oracleID string) ([]byte, error) {
+	oracleKey, err := ctx.GetStub().CreateCompositeKey(oracleObjectType, []string{oracleID})
+	if err != nil {
+		return nil, err
+	}
+	oracleJSON, err := ctx.GetStub().GetState(oracleKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	return oracleJSON, nil
+}
+
+func putOracle(ctx contractapi.TransactionContextInterface, oracle *Oracle) error {
+	oracleKey, err := ctx.GetStub().CreateCompositeKey(oracleObjectType, []string{oracle.ID})
+	if err != nil {
+		return err
+	}
+	oracleJSON, err := json.Marshal(oracle)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(oracleKey, oracleJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package gc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/nalle631/fabric-network/chaincode/events"
+)
+
+const (
+	jobAuthorityChangeObjectType = "JobAuthorityChange"
+	jobAuthorityGranted          = "granted"
+	jobAuthorityRevoked          = "revoked"
+
+	// auditTimeLayout is fixed width so that audit keys sort chronologically.
+	auditTimeLayout = "2006-01-02T15:04:05.000000000Z"
+)
+
+// JobAuthorityChange is an audit record of a job authority being granted to or revoked from a technician.
+type JobAuthorityChange struct {
+	TechnicianID string    `json:"TechnicianID"`
+	JobType      string    `json:"JobType"`
+	Action       string    `json:"Action"`
+	ChangedBy    string    `json:"ChangedBy"`
+	ChangedAt    time.Time `json:"ChangedAt"`
+	TxID         string    `json:"TxID"`
+}
+
+// GrantJobAuthority allows a technician to take jobs of the given job type.
+func (s *SmartContract) GrantJobAuthority(ctx contractapi.TransactionContextInterface, technicianID string, jobType string) error {
+	_, err := requireRole(ctx, roleOwnerAdmin)
+	if err != nil {
+		return err
+	}
+
+	gc, err := readGeneralContract(ctx, technicianID)
+	if err != nil {
+		return err
+	}
+
+	if hasJobAuthority(gc, jobType) {
+		return fmt.Errorf("%s already has authority for %s jobs", technicianID, jobType)
+	}
+	gc.JobAuthority = append(gc.JobAuthority, jobType)
+
+	return putJobAuthorityChange(ctx, gc, jobType, jobAuthorityGranted)
+}
+
+// RevokeJobAuthority stops a technician from taking new jobs of the given job type.
+func (s *SmartContract) RevokeJobAuthority(ctx contractapi.TransactionContextInterface, technicianID string, jobType string) error {
+	_, err := requireRole(ctx, roleOwnerAdmin)
+	if err != nil {
+		return err
+	}
+
+	gc, err := readGeneralContract(ctx, technicianID)
+	if err != nil {
+		return err
+	}
+
+	if !hasJobAuthority(gc, jobType) {
+		return fmt.Errorf("%s does not have authority for %s jobs", technicianID, jobType)
+	}
+	jobAuthority := []string{}
+	for _, authority := range gc.JobAuthority {
+		if authority != jobType {
+			jobAuthority = append(jobAuthority, authority)
+		}
+	}
+	gc.JobAuthority = jobAuthority
+
+	return putJobAuthorityChange(ctx, gc, jobType, jobAuthorityRevoked)
+}
+
+// GetJobAuthorityHistory returns every grant and revocation of job authority for a technician, oldest first.
+func (s *SmartContract) GetJobAuthorityHistory(ctx contractapi.TransactionContextInterface, technicianID string) ([]*JobAuthorityChange, error) {
+	_, err := authorizeRead(ctx, technicianID)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(jobAuthorityChangeObjectType, []string{technicianID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	changes := []*JobAuthorityChange{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var change JobAuthorityChange
+		err = json.Unmarshal(queryResponse.Value, &change)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, &change)
+	}
+
+	return changes, nil
+}
+
+// putJobAuthorityChange stores the updated general contract together with an audit record of the change
+// and emits JobAuthorityChanged.
+func putJobAuthorityChange(ctx contractapi.TransactionContextInterface, gc *GeneralContract, jobType string, action string) error {
+	changedBy, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return err
+	}
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+	changedAt := txTimestamp.AsTime().UTC()
+
+	change := JobAuthorityChange{
+		TechnicianID: gc.TechnicianID,
+		JobType:      jobType,
+		Action:       action,
+		ChangedBy:    changedBy,
+		ChangedAt:    changedAt,
+		TxID:         ctx.GetStub().GetTxID(),
+	}
+	changeKey, err := ctx.GetStub().CreateCompositeKey(jobAuthorityChangeObjectType, []string{gc.TechnicianID, changedAt.Format(auditTimeLayout), change.TxID})
+	if err != nil {
+		return err
+	}
+	changeJSON, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(changeKey, changeJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	err = putGeneralContract(ctx, gc)
+	if err != nil {
+		return err
+	}
+
+	return events.Emit(ctx.GetStub(), events.JobAuthorityChanged{
+		Version:      events.Version,
+		TechnicianID: gc.TechnicianID,
+		JobType:      jobType,
+		Action:       action,
+		ChangedAt:    changedAt,
+	})
+}
+
+func hasJobAuthority(gc *GeneralContract, jobType string) bool {
+	for _, authority := range gc.JobAuthority {
+		if authority == jobType {
+			return true
+		}
+	}
+	return false
+}
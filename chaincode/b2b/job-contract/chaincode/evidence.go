@@ -0,0 +1,160 @@
+package gc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// evidenceTransientKey is the key of the completion evidence in the transient map of SubmitCompletion.
+	evidenceTransientKey = "evidence"
+	evidenceObjectType   = "evidence"
+)
+
+// CompletionEvidence is what the technician submits to prove the work of a job was done. It is kept in
+// the private data collection shared by the technician organisation and the owner, see evidenceCollection.
+type CompletionEvidence struct {
+	JobID             string         `json:"JobID"`
+	TechnicianID      string         `json:"TechnicianID"`
+	Files             []EvidenceFile `json:"Files"`
+	Latitude          float64        `json:"Latitude"`
+	Longitude         float64        `json:"Longitude"`
+	PartSerialNumbers []string       `json:"PartSerialNumbers"`
+	SubmittedAt       time.Time      `json:"SubmittedAt"`
+}
+
+// EvidenceFile is a photo or log file of the evidence, the file itself stays with the technician.
+type EvidenceFile struct {
+	Name   string `json:"Name"`
+	Kind   string `json:"Kind"`
+	SHA256 string `json:"SHA256"`
+}
+
+// EvidenceSummary is the public part of the evidence of a job, it only holds hashes. EvidenceHash
+// is the SHA-256 hash of the private CompletionEvidence as stored in Collection.
+type EvidenceSummary struct {
+	Collection   string   `json:"Collection"`
+	EvidenceHash string   `json:"EvidenceHash"`
+	FileHashes   []string `json:"FileHashes"`
+}
+
+// ReadCompletionEvidence returns the private evidence of a job, checked against the hash in public state.
+// Only peers of the technician organisation and the owner have the evidence.
+func (s *SmartContract) ReadCompletionEvidence(ctx contractapi.TransactionContextInterface, technicianID string, jobID string) (*CompletionEvidence, error) {
+	_, err := authorizeRead(ctx, technicianID)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := readJob(ctx, jobID, technicianID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Evidence == nil {
+		return nil, fmt.Errorf("job %s has no completion evidence", jobID)
+	}
+
+	evidenceKey, err := ctx.GetStub().CreateCompositeKey(evidenceObjectType, []string{jobID})
+	if err != nil {
+		return nil, err
+	}
+	evidenceJSON, err := ctx.GetStub().GetPrivateData(job.Evidence.Collection, evidenceKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from private data collection %s: %v", job.Evidence.Collection, err)
+	}
+	if evidenceJSON == nil {
+		return nil, fmt.Errorf("the evidence of job %s is not available on this peer", jobID)
+	}
+	hash := sha256.Sum256(evidenceJSON)
+	if hex.EncodeToString(hash[:]) != job.Evidence.EvidenceHash {
+		return nil, fmt.Errorf("the evidence of job %s does not match its hash on the ledger", jobID)
+	}
+
+	var evidence CompletionEvidence
+	err = json.Unmarshal(evidenceJSON, &evidence)
+	if err != nil {
+		return nil, err
+	}
+
+	return &evidence, nil
+}
+
+// putCompletionEvidence stores the evidence passed in the transient map of the transaction in the
+// private data collection of the technician and sets its summary on the job.
+func putCompletionEvidence(ctx contractapi.TransactionContextInterface, technicianID string, job *Job) error {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to read the transient map: %v", err)
+	}
+	evidenceInput, ok := transientMap[evidenceTransientKey]
+	if !ok {
+		return fmt.Errorf("completion evidence must be passed in the transient map under %q", evidenceTransientKey)
+	}
+
+	var evidence CompletionEvidence
+	err = json.Unmarshal(evidenceInput, &evidence)
+	if err != nil {
+		return fmt.Errorf("invalid completion evidence: %v", err)
+	}
+	if len(evidence.Files) == 0 {
+		return fmt.Errorf("completion evidence must have at least one photo or log file")
+	}
+	fileHashes := []string{}
+	for _, file := range evidence.Files {
+		hash, err := hex.DecodeString(file.SHA256)
+		if err != nil || len(hash) != 32 {
+			return fmt.Errorf("hash of %s is not a hex encoded SHA-256 hash", file.Name)
+		}
+		if file.Kind != "photo" && file.Kind != "log" {
+			return fmt.Errorf("kind of %s must be photo or log, got %s", file.Name, file.Kind)
+		}
+		fileHashes = append(fileHashes, file.SHA256)
+	}
+	if evidence.Latitude < -90 || evidence.Latitude > 90 || evidence.Longitude < -180 || evidence.Longitude > 180 {
+		return fmt.Errorf("invalid GPS coordinates %f, %f", evidence.Latitude, evidence.Longitude)
+	}
+	if evidence.PartSerialNumbers == nil {
+		evidence.PartSerialNumbers = []string{}
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+	evidence.JobID = job.ID
+	evidence.TechnicianID = technicianID
+	evidence.SubmittedAt = txTimestamp.AsTime()
+
+	evidenceJSON, err := json.Marshal(evidence)
+	if err != nil {
+		return err
+	}
+	evidenceKey, err := ctx.GetStub().CreateCompositeKey(evidenceObjectType, []string{job.ID})
+	if err != nil {
+		return err
+	}
+	collection := evidenceCollection(technicianID)
+	err = ctx.GetStub().PutPrivateData(collection, evidenceKey, evidenceJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to private data collection %s: %v", collection, err)
+	}
+
+	hash := sha256.Sum256(evidenceJSON)
+	job.Evidence = &EvidenceSummary{
+		Collection:   collection,
+		EvidenceHash: hex.EncodeToString(hash[:]),
+		FileHashes:   fileHashes,
+	}
+	return nil
+}
+
+// evidenceCollection is the private data collection shared by the technician organisation and the owner.
+// Every technician organisation needs one in collections_config.json.
+func evidenceCollection(technicianID string) string {
+	return "evidence_" + technicianID
+}
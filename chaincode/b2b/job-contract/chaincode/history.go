@@ -0,0 +1,132 @@
+package gc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// JobVersion is one version of a job on the ledger. Job is not set on a version that deleted the job, as
+// releasing and reassigning a job did before those records were kept with the status Released or Reassigned.
+type JobVersion struct {
+	TxID      string    `json:"TxID"`
+	Timestamp time.Time `json:"Timestamp"`
+	IsDelete  bool      `json:"IsDelete"`
+	Job       *Job      `json:"Job,omitempty"`
+}
+
+// GeneralContractVersion is one version of a general contract on the ledger. MonthlyBalance and
+// UnbilledJobs are only stored on general contracts from before jobs had records of their own.
+type GeneralContractVersion struct {
+	TxID            string           `json:"TxID"`
+	Timestamp       time.Time        `json:"Timestamp"`
+	IsDelete        bool             `json:"IsDelete"`
+	GeneralContract *GeneralContract `json:"GeneralContract,omitempty"`
+}
+
+// GetJobHistory returns every version of a job, oldest first. Changes made while the job was kept in the
+// general contract, before MigrateJobs, are in the history of the general contract.
+func (s *SmartContract) GetJobHistory(ctx contractapi.TransactionContextInterface, technicianID string, jobID string) ([]*JobVersion, error) {
+	_, err := authorizeRead(ctx, technicianID)
+	if err != nil {
+		return nil, err
+	}
+
+	jobKey, err := ctx.GetStub().CreateCompositeKey(jobObjectType, []string{technicianID, jobID})
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []*JobVersion{}
+	err = forEachVersion(ctx, jobKey, func(txID string, timestamp time.Time, isDelete bool, value []byte) error {
+		version := JobVersion{TxID: txID, Timestamp: timestamp, IsDelete: isDelete}
+		if !isDelete {
+			version.Job = &Job{}
+			err := json.Unmarshal(value, version.Job)
+			if err != nil {
+				return err
+			}
+		}
+		versions = append(versions, &version)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("the job %s has no history", jobID)
+	}
+
+	return versions, nil
+}
+
+// GetGeneralContractHistory returns every version of the general contract of a technician, oldest first.
+func (s *SmartContract) GetGeneralContractHistory(ctx contractapi.TransactionContextInterface, technicianID string) ([]*GeneralContractVersion, error) {
+	_, err := authorizeRead(ctx, technicianID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []*GeneralContractVersion{}
+	err = forEachVersion(ctx, technicianID, func(txID string, timestamp time.Time, isDelete bool, value []byte) error {
+		version := GeneralContractVersion{TxID: txID, Timestamp: timestamp, IsDelete: isDelete}
+		if !isDelete {
+			version.GeneralContract = &GeneralContract{}
+			err := json.Unmarshal(value, version.GeneralContract)
+			if err != nil {
+				return err
+			}
+		}
+		versions = append(versions, &version)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("the general contract of %s has no history", technicianID)
+	}
+
+	return versions, nil
+}
+
+// forEachVersion calls fn with every version of key, oldest first. The value of a delete is empty.
+func forEachVersion(ctx contractapi.TransactionContextInterface, key string, fn func(txID string, timestamp time.Time, isDelete bool, value []byte) error) error {
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to read history from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	// the history is returned newest first
+	type keyVersion struct {
+		txID      string
+		timestamp time.Time
+		isDelete  bool
+		value     []byte
+	}
+	keyVersions := []keyVersion{}
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+		keyVersions = append(keyVersions, keyVersion{
+			txID:      modification.TxId,
+			timestamp: modification.Timestamp.AsTime(),
+			isDelete:  modification.IsDelete,
+			value:     modification.Value,
+		})
+	}
+
+	for i := len(keyVersions) - 1; i >= 0; i-- {
+		v := keyVersions[i]
+		err = fn(v.txID, v.timestamp, v.isDelete, v.value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package gc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/nalle631/fabric-network/chaincode/events"
+)
+
+const (
+	// VerdictCorrectError means the technician found and fixed the error the job was created for,
+	// the job is paid JobPay and InspectionPay.
+	VerdictCorrectError = "correct-error"
+	// VerdictWrongError means the job was created for the wrong error, only InspectionPay is paid.
+	VerdictWrongError = "wrong-error"
+
+	// verification modes of a service definition
+	verificationInspection   = "inspection"
+	verificationSelfReported = "self-reported"
+)
+
+// InspectionReport is what an inspector found when inspecting a completed job. EvidenceHashes
+// are hex encoded SHA-256 hashes of the photos and documents the verdict is based on.
+type InspectionReport struct {
+	Verdict        string    `json:"Verdict"`
+	FaultCode      string    `json:"FaultCode"`
+	Notes          string    `json:"Notes"`
+	EvidenceHashes []string  `json:"EvidenceHashes"`
+	InspectorMSPID string    `json:"InspectorMSPID"`
+	InspectorID    string    `json:"InspectorID"`
+	InspectedAt    time.Time `json:"InspectedAt"`
+}
+
+// SubmitCompletion reports that the technician has finished the work, with the evidence in the transient
+// map under "evidence". The job waits for an inspection, unless the service of the job is self-reported,
+// then it is done and paid in full right away.
+func (s *SmartContract) SubmitCompletion(ctx contractapi.TransactionContextInterface, jobID string) (*Job, error) {
+	technician, err := requireRole(ctx, roleTechnician)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := readJob(ctx, jobID, technician.MSPID)
+	if err != nil {
+		return nil, err
+	}
+	err = applyTransition(ctx, job, technician, JobStatusAwaitingInspection)
+	if err != nil {
+		return nil, err
+	}
+	err = putCompletionEvidence(ctx, technician.MSPID, job)
+	if err != nil {
+		return nil, err
+	}
+
+	verificationMode, err := jobVerificationMode(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	if verificationMode == verificationSelfReported {
+		err = applyTransition(ctx, job, technician, JobStatusDone)
+		if err != nil {
+			return nil, err
+		}
+		// the payout is added to the monthly balance when the general contract is read
+		err = completeJob(ctx, job, job.JobPay+job.InspectionPay, submittedAt(job))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = putJob(ctx, technician.MSPID, job)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status == JobStatusDone {
+		err = emitJobCompleted(ctx, technician.MSPID, job)
+	} else {
+		err = events.Emit(ctx.GetStub(), events.JobSubmitted{
+			Version:      events.Version,
+			TechnicianID: technician.MSPID,
+			JobID:        job.ID,
+			EvidenceHash: job.Evidence.EvidenceHash,
+			SubmittedAt:  submittedAt(job),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// RecordInspection records the inspection of a job awaiting inspection and completes it. The payout
+// is decided by the verdict, and any late penalty by when the technician submitted the job.
+func (s *SmartContract) RecordInspection(ctx contractapi.TransactionContextInterface, technicianID string, jobID string, verdict string, faultCode string, notes string, evidenceHashes []string) (*Job, error) {
+	inspector, err := requireRole(ctx, roleInspector, roleOwnerAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, evidenceHash := range evidenceHashes {
+		hash, err := hex.DecodeString(evidenceHash)
+		if err != nil || len(hash) != 32 {
+			return nil, fmt.Errorf("evidence hash %s is not a hex encoded SHA-256 hash", evidenceHash)
+		}
+	}
+	if evidenceHashes == nil {
+		evidenceHashes = []string{}
+	}
+
+	job, err := readJob(ctx, jobID, technicianID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pay int
+	switch verdict {
+	case VerdictCorrectError:
+		pay = job.JobPay + job.InspectionPay
+	case VerdictWrongError:
+		pay = job.InspectionPay
+	default:
+		return nil, fmt.Errorf("verdict must be %s or %s, got %s", VerdictCorrectError, VerdictWrongError, verdict)
+	}
+
+	err = applyTransition(ctx, job, inspector, JobStatusDone)
+	if err != nil {
+		return nil, err
+	}
+	// the payout is added to the monthly balance when the general contract is read
+	err = completeJob(ctx, job, pay, submittedAt(job))
+	if err != nil {
+		return nil, err
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	job.Inspection = &InspectionReport{
+		Verdict:        verdict,
+		FaultCode:      faultCode,
+		Notes:          notes,
+		EvidenceHashes: evidenceHashes,
+		InspectorMSPID: inspector.MSPID,
+		InspectorID:    inspector.ID,
+		InspectedAt:    txTimestamp.AsTime(),
+	}
+
+	err = putJob(ctx, technicianID, job)
+	if err != nil {
+		return nil, err
+	}
+
+	err = emitJobCompleted(ctx, technicianID, job)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// emitJobCompleted sets the JobCompleted event of a job that has just been completed.
+func emitJobCompleted(ctx contractapi.TransactionContextInterface, technicianID string, job *Job) error {
+	event := events.JobCompleted{
+		Version:      events.Version,
+		TechnicianID: technicianID,
+		JobID:        job.ID,
+		Payout:       job.Payout,
+		Penalty:      job.Penalty,
+		DaysLate:     job.DaysLate,
+	}
+	if job.Inspection != nil {
+		event.Verdict = job.Inspection.Verdict
+	}
+	if job.CompletedAt != nil {
+		event.CompletedAt = *job.CompletedAt
+	}
+	return events.Emit(ctx.GetStub(), event)
+}
+
+// jobVerificationMode returns how the work of job is verified. Jobs taken before job types were
+// recorded are always inspected.
+func jobVerificationMode(ctx contractapi.TransactionContextInterface, job *Job) (string, error) {
+	if job.EventType == "" {
+		return verificationInspection, nil
+	}
+	jobType, err := readJobType(ctx, job.EventType)
+	if err != nil {
+		return "", err
+	}
+	definition, err := readServiceDefinition(ctx, jobType)
+	if err != nil {
+		return "", err
+	}
+	return definition.VerificationMode, nil
+}
+
+// submittedAt returns when the job was submitted for inspection.
+func submittedAt(job *Job) time.Time {
+	for i := len(job.Transitions) - 1; i >= 0; i-- {
+		if job.Transitions[i].To == JobStatusAwaitingInspection {
+			return job.Transitions[i].At
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,279 @@
+package gc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// jobObjectType is the composite key prefix of jobs, keyed by technician and job id. Every job is its own
+// record so that transactions on different jobs of the same technician do not conflict.
+const jobObjectType = "job"
+
+// JobPage is one page of jobs from GetJobsPage. Bookmark is passed to the next call to get the next page.
+type JobPage struct {
+	Jobs                []*Job `json:"Jobs"`
+	Bookmark            string `json:"Bookmark"`
+	FetchedRecordsCount int32  `json:"FetchedRecordsCount"`
+}
+
+// GetJobsPage returns up to pageSize jobs of a technician, starting after bookmark.
+// Pagination is only available when the transaction is evaluated, not submitted.
+func (s *SmartContract) GetJobsPage(ctx contractapi.TransactionContextInterface, technicianID string, pageSize int32, bookmark string) (*JobPage, error) {
+	_, err := authorizeRead(ctx, technicianID)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(jobObjectType, []string{technicianID}, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	jobs := []*Job{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var job Job
+		err = json.Unmarshal(queryResponse.Value, &job)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return &JobPage{
+		Jobs:                jobs,
+		Bookmark:            metadata.Bookmark,
+		FetchedRecordsCount: metadata.FetchedRecordsCount,
+	}, nil
+}
+
+// MigrateJobs moves the jobs of a general contract from before jobs had their own records out of
+// the general contract. The monthly balance that can not be traced to a job is carried over.
+func (s *SmartContract) MigrateJobs(ctx contractapi.TransactionContextInterface, technicianID string) (*GeneralContract, error) {
+	_, err := requireRole(ctx, roleOwnerAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	gc, err := readGeneralContract(ctx, technicianID)
+	if err != nil {
+		return nil, err
+	}
+	if len(gc.Jobs) == 0 {
+		return nil, fmt.Errorf("general contract of %s has no jobs to migrate", technicianID)
+	}
+
+	carriedOverBalance := migrateJobsBalance(gc)
+	for i := range gc.Jobs {
+		job := &gc.Jobs[i]
+		exists, err := jobExistsOnLedger(ctx, job.ID, technicianID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, fmt.Errorf("job %s of %s has already been migrated", job.ID, technicianID)
+		}
+		err = putJob(ctx, technicianID, job)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	gc.CarriedOverBalance = carriedOverBalance
+	gc.MonthlyBalance = 0
+	gc.UnbilledJobs = nil
+	gc.Jobs = nil
+	err = putGeneralContract(ctx, gc)
+	if err != nil {
+		return nil, err
+	}
+
+	return summarizeGeneralContract(ctx, gc)
+}
+
+// migrateJobsBalance marks the done jobs of gc that were already paid out with the last payout period
+// and returns the part of the monthly balance that can not be traced to an unbilled job.
+func migrateJobsBalance(gc *GeneralContract) int {
+	tracedBalance := 0
+	for i := range gc.Jobs {
+		job := &gc.Jobs[i]
+		if job.Status == JobStatusDone {
+			if containsString(gc.UnbilledJobs, job.ID) || gc.LastPayoutPeriod == "" {
+				tracedBalance += job.Payout
+			} else {
+				job.PayoutPeriod = gc.LastPayoutPeriod
+			}
+		}
+	}
+	return gc.MonthlyBalance - tracedBalance
+}
+
+func putJob(ctx contractapi.TransactionContextInterface, technicianID string, job *Job) error {
+	jobKey, err := ctx.GetStub().CreateCompositeKey(jobObjectType, []string{technicianID, job.ID})
+	if err != nil {
+		return err
+	}
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(jobKey, jobJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+	return nil
+}
+
+// previousJobRecord returns the record a technician has of a job it released or that was reassigned from
+// it, which is replaced when the job comes back to the technician, or nil when it has none. It fails when
+// the technician still has the job.
+func previousJobRecord(ctx contractapi.TransactionContextInterface, jobID string, technicianID string) (*Job, error) {
+	exists, err := jobExistsOnLedger(ctx, jobID, technicianID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	job, err := readJob(ctx, jobID, technicianID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != JobStatusReleased && job.Status != JobStatusReassigned {
+		return nil, fmt.Errorf("Job %s already exists on ledger", jobID)
+	}
+	return job, nil
+}
+
+func readJob(ctx contractapi.TransactionContextInterface, jobID string, technicianID string) (*Job, error) {
+	jobJSON, err := getJobState(ctx, jobID, technicianID)
+	if err != nil {
+		return nil, err
+	}
+	if jobJSON == nil {
+		return nil, fmt.Errorf("the job %s does not exist", jobID)
+	}
+
+	var job Job
+	err = json.Unmarshal(jobJSON, &job)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func jobExistsOnLedger(ctx contractapi.TransactionContextInterface, jobID string, technicianID string) (bool, error) {
+	jobJSON, err := getJobState(ctx, jobID, technicianID)
+	if err != nil {
+		return false, err
+	}
+	return jobJSON != nil, nil
+}
+
+func getJobState(ctx contractapi.TransactionContextInterface, jobID string, technicianID string) ([]byte, error) {
+	jobKey, err := ctx.GetStub().CreateCompositeKey(jobObjectType, []string{technicianID, jobID})
+	if err != nil {
+		return nil, err
+	}
+	jobJSON, err := ctx.GetStub().GetState(jobKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	return jobJSON, nil
+}
+
+// getJobs returns every job of a technician.
+func getJobs(ctx contractapi.TransactionContextInterface, technicianID string) ([]*Job, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(jobObjectType, []string{technicianID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	jobs := []*Job{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var job Job
+		err = json.Unmarshal(queryResponse.Value, &job)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, nil
+}
+
+// unbilledJobs returns the done jobs, and cancelled jobs with a compensation, that have not been
+// included in a payout statement yet.
+func unbilledJobs(jobs []*Job) []*Job {
+	unbilled := []*Job{}
+	for _, job := range jobs {
+		billable := job.Status == JobStatusDone || (job.Status == JobStatusCancelled && job.Payout > 0)
+		if billable && job.PayoutPeriod == "" {
+			unbilled = append(unbilled, job)
+		}
+	}
+	return unbilled
+}
+
+// summarizeGeneralContract fills in MonthlyBalance and UnbilledJobs of gc from the jobs of the technician.
+func summarizeGeneralContract(ctx contractapi.TransactionContextInterface, gc *GeneralContract) (*GeneralContract, error) {
+	if len(gc.Jobs) > 0 {
+		// not migrated yet, the stored balance is still the one that counts
+		return gc, nil
+	}
+
+	jobs, err := getJobs(ctx, gc.TechnicianID)
+	if err != nil {
+		return nil, err
+	}
+
+	gc.MonthlyBalance = gc.CarriedOverBalance
+	gc.UnbilledJobs = []string{}
+	for _, job := range unbilledJobs(jobs) {
+		gc.MonthlyBalance += job.Payout
+		gc.UnbilledJobs = append(gc.UnbilledJobs, job.ID)
+	}
+
+	return gc, nil
+}
+
+// storedGeneralContract returns gc as it is put to the world state. MonthlyBalance and UnbilledJobs
+// are derived from the jobs when the general contract is read, so they are only stored while the
+// jobs have not been migrated yet.
+func storedGeneralContract(gc GeneralContract) GeneralContract {
+	if len(gc.Jobs) == 0 {
+		gc.MonthlyBalance = 0
+		gc.UnbilledJobs = nil
+	}
+	return gc
+}
+
+// putGeneralContract stores the summary fields of gc.
+func putGeneralContract(ctx contractapi.TransactionContextInterface, gc *GeneralContract) error {
+	gcJSON, err := json.Marshal(storedGeneralContract(*gc))
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(gc.TechnicianID, gcJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package gc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMigrateJobsBalance(t *testing.T) {
+	legacy := func() GeneralContract {
+		return GeneralContract{
+			TechnicianID:     "technician1",
+			MonthlyBalance:   250,
+			JobAuthority:     []string{"mowing"},
+			UnbilledJobs:     []string{"job2", "job3"},
+			LastPayoutPeriod: "2024-04",
+			Jobs: []Job{
+				{ID: "job1", Status: JobStatusDone, Payout: 100},
+				{ID: "job2", Status: JobStatusDone, Payout: 100},
+				{ID: "job3", Status: JobStatusDone, Payout: 120},
+				{ID: "job4", Status: JobStatusInProgress, Payout: 80},
+			},
+		}
+	}
+	// roundTrip puts and reads gc the way a transaction between the grant and MigrateJobs does.
+	roundTrip := func(t *testing.T, gc GeneralContract) GeneralContract {
+		gcJSON, err := json.Marshal(storedGeneralContract(gc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var read GeneralContract
+		err = json.Unmarshal(gcJSON, &read)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return read
+	}
+
+	tests := []struct {
+		name   string
+		change func(gc *GeneralContract)
+	}{
+		{"migrated directly", func(gc *GeneralContract) {}},
+		{"granted before migrating", func(gc *GeneralContract) { gc.JobAuthority = append(gc.JobAuthority, "hedging") }},
+		{"revoked before migrating", func(gc *GeneralContract) { gc.JobAuthority = nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gc := legacy()
+			tt.change(&gc)
+			gc = roundTrip(t, gc)
+
+			got := migrateJobsBalance(&gc)
+			if got != 30 {
+				t.Errorf("migrateJobsBalance() = %d, want 30", got)
+			}
+			if gc.Jobs[0].PayoutPeriod != "2024-04" {
+				t.Errorf("paid out job1 has payout period %q, want 2024-04", gc.Jobs[0].PayoutPeriod)
+			}
+			if gc.Jobs[1].PayoutPeriod != "" {
+				t.Errorf("unbilled job2 has payout period %q, want none", gc.Jobs[1].PayoutPeriod)
+			}
+		})
+	}
+}
+
+func TestStoredGeneralContract(t *testing.T) {
+	migrated := GeneralContract{TechnicianID: "technician1", MonthlyBalance: 130, CarriedOverBalance: 30, UnbilledJobs: []string{"job2"}}
+	stored := storedGeneralContract(migrated)
+	if stored.MonthlyBalance != 0 || stored.UnbilledJobs != nil {
+		t.Errorf("storedGeneralContract() of a migrated contract = %+v, want no MonthlyBalance and UnbilledJobs", stored)
+	}
+	if stored.CarriedOverBalance != 30 {
+		t.Errorf("storedGeneralContract() CarriedOverBalance = %d, want 30", stored.CarriedOverBalance)
+	}
+}
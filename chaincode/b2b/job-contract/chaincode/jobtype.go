@@ -0,0 +1,312 @@
+package gc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const jobTypeObjectType = "JobType"
+
+// JobType is an entry in the job type registry. It maps an event type from the
+// work order system to the service chaincode that creates jobs of that type.
+// The pay of the jobs is set by the rate card of the service chaincode.
+type JobType struct {
+	EventType     string       `json:"EventType"`
+	ChaincodeName string       `json:"ChaincodeName"`
+	DisplayName   string       `json:"DisplayName"`
+	DeadlineDays  DeadlineDays `json:"DeadlineDays"`
+	Deprecated    bool         `json:"Deprecated"`
+}
+
+// DeadlineDays is the number of days a technician has to complete a job per service level.
+type DeadlineDays struct {
+	Standard int `json:"Standard"`
+	Gold     int `json:"Gold"`
+	Platinum int `json:"Platinum"`
+}
+
+func (d DeadlineDays) forServiceLevel(serviceLevel string) (int, error) {
+	switch serviceLevel {
+	case "standard":
+		return d.Standard, nil
+	case "gold":
+		return d.Gold, nil
+	case "platinum":
+		return d.Platinum, nil
+	default:
+		return 0, fmt.Errorf("unknown service level %s", serviceLevel)
+	}
+}
+
+// RegisterJobType adds a new job type to the registry.
+func (s *SmartContract) RegisterJobType(ctx contractapi.TransactionContextInterface, eventType string, chaincodeName string, displayName string, standardDays int, goldDays int, platinumDays int) (*JobType, error) {
+	_, err := requireRole(ctx, roleOwnerAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	jobTypeJSON, err := getJobTypeState(ctx, eventType)
+	if err != nil {
+		return nil, err
+	}
+	if jobTypeJSON != nil {
+		return nil, fmt.Errorf("job type %s is already registered", eventType)
+	}
+
+	jobType := JobType{
+		EventType:     eventType,
+		ChaincodeName: chaincodeName,
+		DisplayName:   displayName,
+		DeadlineDays: DeadlineDays{
+			Standard: standardDays,
+			Gold:     goldDays,
+			Platinum: platinumDays,
+		},
+	}
+	err = putJobType(ctx, &jobType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jobType, nil
+}
+
+// UpdateJobType changes the chaincode, display name and deadlines of a registered job type.
+// Jobs that have already been taken keep the values they were created with.
+func (s *SmartContract) UpdateJobType(ctx contractapi.TransactionContextInterface, eventType string, chaincodeName string, displayName string, standardDays int, goldDays int, platinumDays int) (*JobType, error) {
+	_, err := requireRole(ctx, roleOwnerAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	jobType, err := readJobType(ctx, eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	jobType.ChaincodeName = chaincodeName
+	jobType.DisplayName = displayName
+	jobType.DeadlineDays = DeadlineDays{
+		Standard: standardDays,
+		Gold:     goldDays,
+		Platinum: platinumDays,
+	}
+	err = putJobType(ctx, jobType)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobType, nil
+}
+
+// DeprecateJobType stops new jobs of a job type from being taken. The job type stays in the registry.
+func (s *SmartContract) DeprecateJobType(ctx contractapi.TransactionContextInterface, eventType string) (*JobType, error) {
+	_, err := requireRole(ctx, roleOwnerAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	jobType, err := readJobType(ctx, eventType)
+	if err != nil {
+		return nil, err
+	}
+	if jobType.Deprecated {
+		return nil, fmt.Errorf("job type %s is already deprecated", eventType)
+	}
+
+	jobType.Deprecated = true
+	err = putJobType(ctx, jobType)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobType, nil
+}
+
+// ReadJobType returns a job type from the registry.
+func (s *SmartContract) ReadJobType(ctx contractapi.TransactionContextInterface, eventType string) (*JobType, error) {
+	return readJobType(ctx, eventType)
+}
+
+// ListJobTypes returns every job type in the registry, including deprecated ones.
+func (s *SmartContract) ListJobTypes(ctx contractapi.TransactionContextInterface) ([]*JobType, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(jobTypeObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	jobTypes := []*JobType{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var jobType JobType
+		err = json.Unmarshal(queryResponse.Value, &jobType)
+		if err != nil {
+			return nil, err
+		}
+		jobTypes = append(jobTypes, &jobType)
+	}
+
+	return jobTypes, nil
+}
+
+// ServiceDefinition is the configuration of a deployment of the service chaincode.
+type ServiceDefinition struct {
+	Type              string `json:"Type"`
+	JobPay            int    `json:"JobPay"`
+	InspectionPay     int    `json:"InspectionPay"`
+	RequiredAuthority string `json:"RequiredAuthority,omitempty"`
+	VerificationMode  string `json:"VerificationMode"`
+}
+
+// requiredJobAuthority returns the job authority a technician needs to take jobs of jobType. It is the
+// required authority of the service chaincode if it has one, otherwise the event type.
+func requiredJobAuthority(ctx contractapi.TransactionContextInterface, jobType *JobType) (string, error) {
+	definition, err := readServiceDefinition(ctx, jobType)
+	if err != nil {
+		return "", err
+	}
+	if definition.RequiredAuthority == "" {
+		return jobType.EventType, nil
+	}
+	return definition.RequiredAuthority, nil
+}
+
+func readServiceDefinition(ctx contractapi.TransactionContextInterface, jobType *JobType) (*ServiceDefinition, error) {
+	invokeArgs := [][]byte{[]byte("ReadServiceDefinition")}
+	response := ctx.GetStub().InvokeChaincode(jobType.ChaincodeName, invokeArgs, ctx.GetStub().GetChannelID())
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to read the service definition of %s: %s", jobType.ChaincodeName, response.Message)
+	}
+
+	var definition ServiceDefinition
+	err := json.Unmarshal(response.Payload, &definition)
+	if err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// serviceJobTransactions are the transactions of the service chaincode that keep its record of a job in
+// the same status as the job contract. EnRoute and AwaitingInspection are not tracked by the service chaincode.
+var serviceJobTransactions = map[string]string{
+	JobStatusAssigned:   "Reassign",
+	JobStatusInProgress: "Start",
+	JobStatusDone:       "Complete",
+	JobStatusFailed:     "Fail",
+	JobStatusCancelled:  "Cancel",
+	JobStatusExpired:    "Expire",
+}
+
+// syncServiceJob updates the job in the service chaincode that created it to the status of job.
+func syncServiceJob(ctx contractapi.TransactionContextInterface, job *Job) error {
+	transaction, ok := serviceJobTransactions[job.Status]
+	if !ok || job.EventType == "" {
+		// jobs taken before job types were recorded can not be traced to their service chaincode
+		return nil
+	}
+	jobType, err := readJobType(ctx, job.EventType)
+	if err != nil {
+		return err
+	}
+
+	invokeArgs := [][]byte{[]byte(transaction), []byte(job.ID)}
+	response := ctx.GetStub().InvokeChaincode(jobType.ChaincodeName, invokeArgs, ctx.GetStub().GetChannelID())
+	if response.Status != shim.OK {
+		return fmt.Errorf("failed to update job %s in %s: %s", job.ID, jobType.ChaincodeName, response.Message)
+	}
+	return nil
+}
+
+// releaseServiceJob frees a job in the service chaincode that created it.
+func releaseServiceJob(ctx contractapi.TransactionContextInterface, job *Job) error {
+	if job.EventType == "" {
+		return fmt.Errorf("job %s was taken before job types were recorded and can not be released", job.ID)
+	}
+	jobType, err := readJobType(ctx, job.EventType)
+	if err != nil {
+		return err
+	}
+
+	invokeArgs := [][]byte{[]byte("Release"), []byte(job.ID)}
+	response := ctx.GetStub().InvokeChaincode(jobType.ChaincodeName, invokeArgs, ctx.GetStub().GetChannelID())
+	if response.Status != shim.OK {
+		return fmt.Errorf("failed to release job %s in %s: %s", job.ID, jobType.ChaincodeName, response.Message)
+	}
+	return nil
+}
+
+// activeJobType returns the job type for eventType, failing if it is unknown or deprecated.
+func activeJobType(ctx contractapi.TransactionContextInterface, eventType string) (*JobType, error) {
+	jobType, err := readJobType(ctx, eventType)
+	if err != nil {
+		return nil, err
+	}
+	if jobType.Deprecated {
+		return nil, fmt.Errorf("job type %s is deprecated", eventType)
+	}
+	return jobType, nil
+}
+
+func readJobType(ctx contractapi.TransactionContextInterface, eventType string) (*JobType, error) {
+	jobTypeJSON, err := getJobTypeState(ctx, eventType)
+	if err != nil {
+		return nil, err
+	}
+	if jobTypeJSON == nil {
+		return nil, fmt.Errorf("unknown job type %s", eventType)
+	}
+
+	var jobType JobType
+	err = json.Unmarshal(jobTypeJSON, &jobType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jobType, nil
+}
+
+func getJobTypeState(ctx contractapi.TransactionContextInterface, eventType string) ([]byte, error) {
+	jobTypeKey, err := ctx.GetStub().CreateCompositeKey(jobTypeObjectType, []string{eventType})
+	if err != nil {
+		return nil, err
+	}
+	jobTypeJSON, err := ctx.GetStub().GetState(jobTypeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	return jobTypeJSON, nil
+}
+
+func putJobType(ctx contractapi.TransactionContextInterface, jobType *JobType) error {
+	if jobType.EventType == "" || jobType.ChaincodeName == "" {
+		return fmt.Errorf("event type and chaincode name must not be empty")
+	}
+	for _, serviceLevel := range []string{"standard", "gold", "platinum"} {
+		days, _ := jobType.DeadlineDays.forServiceLevel(serviceLevel)
+		if days <= 0 {
+			return fmt.Errorf("deadline for %s jobs must be at least one day, got %d", serviceLevel, days)
+		}
+	}
+
+	jobTypeKey, err := ctx.GetStub().CreateCompositeKey(jobTypeObjectType, []string{jobType.EventType})
+	if err != nil {
+		return err
+	}
+	jobTypeJSON, err := json.Marshal(jobType)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(jobTypeKey, jobTypeJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+	return nil
+}
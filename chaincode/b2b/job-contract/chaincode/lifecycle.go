@@ -0,0 +1,418 @@
+package gc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/nalle631/fabric-network/chaincode/events"
+)
+
+const (
+	JobStatusAssigned           = "Assigned"
+	JobStatusEnRoute            = "EnRoute"
+	JobStatusInProgress         = "InProgress"
+	JobStatusAwaitingInspection = "AwaitingInspection"
+	JobStatusDone               = "Done"
+	JobStatusFailed             = "Failed"
+	JobStatusCancelled          = "Cancelled"
+	JobStatusExpired            = "Expired"
+	// JobStatusReleased and JobStatusReassigned end the record a technician has of a job it gave back or
+	// that was moved to another technician. The record is kept so that its transitions stay on the ledger.
+	JobStatusReleased   = "Released"
+	JobStatusReassigned = "Reassigned"
+
+	// legacyJobStatusOngoing is what the service chaincodes used to set on new jobs, it is treated as Assigned.
+	legacyJobStatusOngoing = "Ongoing"
+)
+
+// JobTransition records a status change of a job, who made it and when.
+type JobTransition struct {
+	From    string    `json:"From"`
+	To      string    `json:"To"`
+	ByMSPID string    `json:"ByMSPID"`
+	ByID    string    `json:"ByID"`
+	ByRole  string    `json:"ByRole"`
+	At      time.Time `json:"At"`
+	TxID    string    `json:"TxID"`
+}
+
+// jobTransitionRule lists the statuses a job may move to a status from and the roles allowed to do it.
+type jobTransitionRule struct {
+	From  []string
+	Roles []string
+}
+
+// releasableJobStatuses are the statuses a technician can release a job from, before work has started.
+var releasableJobStatuses = []string{JobStatusAssigned, JobStatusEnRoute}
+
+var jobTransitionRules = map[string]jobTransitionRule{
+	// only used for the record the new technician gets when the owner reassigns a job, a taken job starts as Assigned
+	JobStatusAssigned: {
+		From:  []string{JobStatusReassigned},
+		Roles: []string{roleOwnerAdmin},
+	},
+	JobStatusEnRoute: {
+		From:  []string{JobStatusAssigned},
+		Roles: []string{roleTechnician},
+	},
+	JobStatusInProgress: {
+		From:  []string{JobStatusEnRoute},
+		Roles: []string{roleTechnician},
+	},
+	JobStatusAwaitingInspection: {
+		From:  []string{JobStatusInProgress},
+		Roles: []string{roleTechnician},
+	},
+	// a technician only moves a job to Done itself when the service is self-reported
+	JobStatusDone: {
+		From:  []string{JobStatusAwaitingInspection},
+		Roles: []string{roleInspector, roleOwnerAdmin},
+	},
+	JobStatusFailed: {
+		From:  []string{JobStatusEnRoute, JobStatusInProgress},
+		Roles: []string{roleTechnician},
+	},
+	JobStatusCancelled: {
+		From:  []string{JobStatusAssigned, JobStatusEnRoute, JobStatusInProgress},
+		Roles: []string{roleOwnerAdmin},
+	},
+	JobStatusExpired: {
+		From:  []string{JobStatusAssigned, JobStatusEnRoute, JobStatusInProgress},
+		Roles: []string{roleOwnerAdmin},
+	},
+	JobStatusReleased: {
+		From:  releasableJobStatuses,
+		Roles: []string{roleTechnician},
+	},
+	JobStatusReassigned: {
+		From:  []string{JobStatusAssigned, JobStatusEnRoute, JobStatusInProgress},
+		Roles: []string{roleOwnerAdmin},
+	},
+}
+
+// StartTravel marks that the technician is on the way to the job.
+func (s *SmartContract) StartTravel(ctx contractapi.TransactionContextInterface, jobID string) (*Job, error) {
+	c, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.changeJobStatus(ctx, c.MSPID, jobID, JobStatusEnRoute)
+}
+
+// StartWork marks that the technician has arrived and started working on the job.
+func (s *SmartContract) StartWork(ctx contractapi.TransactionContextInterface, jobID string) (*Job, error) {
+	c, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.changeJobStatus(ctx, c.MSPID, jobID, JobStatusInProgress)
+}
+
+// FailJob marks that the technician could not complete the job.
+func (s *SmartContract) FailJob(ctx contractapi.TransactionContextInterface, jobID string) (*Job, error) {
+	c, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.changeJobStatus(ctx, c.MSPID, jobID, JobStatusFailed)
+}
+
+// CancelJob lets the owner cancel a job that has not been completed. The technician is paid
+// compensation for the cancelled job in the next payout, pass 0 for no compensation.
+func (s *SmartContract) CancelJob(ctx contractapi.TransactionContextInterface, technicianID string, jobID string, compensation int) (*Job, error) {
+	job, err := readJob(ctx, jobID, technicianID)
+	if err != nil {
+		return nil, err
+	}
+	if compensation < 0 || compensation > job.JobPay+job.InspectionPay {
+		return nil, fmt.Errorf("compensation must be between 0 and the pay of the job %d, got %d", job.JobPay+job.InspectionPay, compensation)
+	}
+
+	job, err = s.transitionJob(ctx, technicianID, jobID, JobStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	if compensation > 0 {
+		job.Payout = compensation
+		err = putJob(ctx, technicianID, job)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = events.Emit(ctx.GetStub(), events.JobCancelled{
+		Version:      events.Version,
+		TechnicianID: technicianID,
+		JobID:        jobID,
+		Compensation: compensation,
+		CancelledAt:  jobCompletedAt(job),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// ReleaseJob gives back a job the technician can not do. The job is released in the service chaincode so
+// that another technician can take it, and the record of the technician ends as Released.
+func (s *SmartContract) ReleaseJob(ctx contractapi.TransactionContextInterface, jobID string) error {
+	technician, err := requireRole(ctx, roleTechnician)
+	if err != nil {
+		return err
+	}
+
+	job, err := readJob(ctx, jobID, technician.MSPID)
+	if err != nil {
+		return err
+	}
+	if !containsString(releasableJobStatuses, job.Status) {
+		return fmt.Errorf("job %s can not be released when it is %s", jobID, job.Status)
+	}
+
+	err = releaseServiceJob(ctx, job)
+	if err != nil {
+		return err
+	}
+	err = applyTransition(ctx, job, technician, JobStatusReleased)
+	if err != nil {
+		return err
+	}
+	err = putJob(ctx, technician.MSPID, job)
+	if err != nil {
+		return err
+	}
+
+	return events.Emit(ctx.GetStub(), events.JobReleased{
+		Version:      events.Version,
+		TechnicianID: technician.MSPID,
+		JobID:        jobID,
+		ReleasedAt:   jobCompletedAt(job),
+	})
+}
+
+// ReassignJob lets the owner move a job that has not been completed to another technician.
+// The record of the old technician ends as Reassigned, and the job starts over as Assigned for the new
+// technician with the transitions it has so far.
+func (s *SmartContract) ReassignJob(ctx contractapi.TransactionContextInterface, technicianID string, jobID string, newTechnicianID string) (*Job, error) {
+	owner, err := requireRole(ctx, roleOwnerAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if technicianID == newTechnicianID {
+		return nil, fmt.Errorf("job %s is already assigned to %s", jobID, newTechnicianID)
+	}
+
+	job, err := readJob(ctx, jobID, technicianID)
+	if err != nil {
+		return nil, err
+	}
+	newGC, err := readGeneralContract(ctx, newTechnicianID)
+	if err != nil {
+		return nil, err
+	}
+	if job.EventType != "" {
+		jobType, err := readJobType(ctx, job.EventType)
+		if err != nil {
+			return nil, err
+		}
+		requiredAuthority, err := requiredJobAuthority(ctx, jobType)
+		if err != nil {
+			return nil, err
+		}
+		if !hasJobAuthority(newGC, requiredAuthority) {
+			return nil, fmt.Errorf("%s is not authorized to take %s jobs", newTechnicianID, requiredAuthority)
+		}
+	}
+	previous, err := previousJobRecord(ctx, jobID, newTechnicianID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = applyTransition(ctx, job, owner, JobStatusReassigned)
+	if err != nil {
+		return nil, err
+	}
+	err = putJob(ctx, technicianID, job)
+	if err != nil {
+		return nil, err
+	}
+
+	// the job record in the service chaincode does not name the technician, only its status is reset
+	reassigned := *job
+	reassigned.Transitions = append([]JobTransition{}, job.Transitions...)
+	reassigned.ReassignedFrom = technicianID
+	if previous != nil {
+		carryOverTransitions(previous, &reassigned)
+	}
+	err = applyTransition(ctx, &reassigned, owner, JobStatusAssigned)
+	if err != nil {
+		return nil, err
+	}
+	err = putJob(ctx, newTechnicianID, &reassigned)
+	if err != nil {
+		return nil, err
+	}
+
+	err = events.Emit(ctx.GetStub(), events.JobReassigned{
+		Version:          events.Version,
+		FromTechnicianID: technicianID,
+		ToTechnicianID:   newTechnicianID,
+		JobID:            jobID,
+		ReassignedAt:     jobCompletedAt(job),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &reassigned, nil
+}
+
+// ExpireJob lets the owner close a job that was not completed before its deadline.
+func (s *SmartContract) ExpireJob(ctx contractapi.TransactionContextInterface, technicianID string, jobID string) (*Job, error) {
+	job, err := readJob(ctx, jobID, technicianID)
+	if err != nil {
+		return nil, err
+	}
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	if !txTimestamp.AsTime().After(job.Deadline) {
+		return nil, fmt.Errorf("job %s has not passed its deadline %s", jobID, job.Deadline)
+	}
+
+	return s.changeJobStatus(ctx, technicianID, jobID, JobStatusExpired)
+}
+
+// changeJobStatus moves a job with transitionJob and emits JobStatusChanged.
+func (s *SmartContract) changeJobStatus(ctx contractapi.TransactionContextInterface, technicianID string, jobID string, to string) (*Job, error) {
+	job, err := s.transitionJob(ctx, technicianID, jobID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	transition := job.Transitions[len(job.Transitions)-1]
+	err = events.Emit(ctx.GetStub(), events.JobStatusChanged{
+		Version:      events.Version,
+		TechnicianID: technicianID,
+		JobID:        jobID,
+		From:         transition.From,
+		To:           transition.To,
+		ChangedAt:    transition.At,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// transitionJob moves a job in the general contract of technicianID to a new status,
+// checking that the transition is valid and that the caller may perform it.
+func (s *SmartContract) transitionJob(ctx contractapi.TransactionContextInterface, technicianID string, jobID string, to string) (*Job, error) {
+	rule, ok := jobTransitionRules[to]
+	if !ok {
+		return nil, fmt.Errorf("unknown job status %s", to)
+	}
+
+	c, err := requireRole(ctx, rule.Roles...)
+	if err != nil {
+		return nil, err
+	}
+	if c.Role == roleTechnician && c.MSPID != technicianID {
+		return nil, fmt.Errorf("%s may not change jobs of %s", c.MSPID, technicianID)
+	}
+
+	job, err := readJob(ctx, jobID, technicianID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = applyTransition(ctx, job, c, to)
+	if err != nil {
+		return nil, err
+	}
+
+	err = putJob(ctx, technicianID, job)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// applyTransition validates that job may move to the status to, records the transition on the job
+// and updates the job in the service chaincode in the same transaction.
+func applyTransition(ctx contractapi.TransactionContextInterface, job *Job, c *caller, to string) error {
+	rule, ok := jobTransitionRules[to]
+	if !ok {
+		return fmt.Errorf("unknown job status %s", to)
+	}
+
+	from := job.Status
+	if from == legacyJobStatusOngoing {
+		from = JobStatusAssigned
+	}
+	if !containsString(rule.From, from) {
+		return fmt.Errorf("job %s can not move from %s to %s", job.ID, job.Status, to)
+	}
+
+	err := recordTransition(ctx, job, c, to)
+	if err != nil {
+		return err
+	}
+	return syncServiceJob(ctx, job)
+}
+
+// recordTransition sets the status of job and appends who changed it and when, using the transaction timestamp.
+func recordTransition(ctx contractapi.TransactionContextInterface, job *Job, c *caller, to string) error {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+
+	job.Transitions = append(job.Transitions, JobTransition{
+		From:    job.Status,
+		To:      to,
+		ByMSPID: c.MSPID,
+		ByID:    c.ID,
+		ByRole:  c.Role,
+		At:      txTimestamp.AsTime(),
+		TxID:    ctx.GetStub().GetTxID(),
+	})
+	job.Status = to
+	return nil
+}
+
+// carryOverTransitions adds the transitions of the previous record a technician had of a job to the new
+// record that replaces it, in the order they were made, unless the new record already has them.
+func carryOverTransitions(previous *Job, job *Job) {
+	// a transaction makes at most one transition to a status on a record
+	recorded := map[string]bool{}
+	for _, transition := range job.Transitions {
+		recorded[transition.TxID+transition.To] = true
+	}
+	transitions := []JobTransition{}
+	for _, transition := range previous.Transitions {
+		if !recorded[transition.TxID+transition.To] {
+			transitions = append(transitions, transition)
+		}
+	}
+	job.Transitions = append(transitions, job.Transitions...)
+	sort.SliceStable(job.Transitions, func(i, j int) bool {
+		return job.Transitions[i].At.Before(job.Transitions[j].At)
+	})
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isTerminalJobStatus(status string) bool {
+	return containsString([]string{JobStatusDone, JobStatusFailed, JobStatusCancelled, JobStatusExpired, JobStatusReleased, JobStatusReassigned}, status)
+}
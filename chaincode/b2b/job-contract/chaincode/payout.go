@@ -0,0 +1,203 @@
+package gc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/nalle631/fabric-network/chaincode/events"
+)
+
+const (
+	payoutStatementObjectType = "PayoutStatement"
+	payoutPeriodLayout        = "2006-01"
+)
+
+// PayoutStatement is the closed balance of a general contract for one payout period.
+// Statements are written once by ClosePayoutPeriod and never updated afterwards.
+type PayoutStatement struct {
+	TechnicianID string    `json:"TechnicianID"`
+	Period       string    `json:"Period"`
+	Amount       int       `json:"Amount"`
+	Jobs         []string  `json:"Jobs"`
+	ClosedAt     time.Time `json:"ClosedAt"`
+}
+
+// ClosePayoutPeriod snapshots the running MonthlyBalance of a general contract into a
+// PayoutStatement for the given period (YYYY-MM) and marks the jobs in it as paid out. Only a period
+// that has ended can be closed, and only the jobs completed before its end are in it, jobs completed
+// later are left for the next period.
+func (s *SmartContract) ClosePayoutPeriod(ctx contractapi.TransactionContextInterface, technicianID string, period string) (*PayoutStatement, error) {
+	periodStart, err := time.Parse(payoutPeriodLayout, period)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payout period %s, expected YYYY-MM: %v", period, err)
+	}
+
+	_, err = requireRole(ctx, roleOwnerAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	periodEnd := periodStart.AddDate(0, 1, 0)
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	if txTimestamp.AsTime().Before(periodEnd) {
+		return nil, fmt.Errorf("payout period %s has not ended yet", period)
+	}
+
+	gc, err := readGeneralContract(ctx, technicianID)
+	if err != nil {
+		return nil, err
+	}
+	if len(gc.Jobs) > 0 {
+		return nil, fmt.Errorf("the jobs of %s must be migrated with MigrateJobs before closing a payout period", technicianID)
+	}
+
+	if gc.LastPayoutPeriod != "" {
+		lastPeriod, err := time.Parse(payoutPeriodLayout, gc.LastPayoutPeriod)
+		if err != nil {
+			return nil, err
+		}
+		if !periodStart.After(lastPeriod) {
+			return nil, fmt.Errorf("payout period %s must be after the last closed period %s", period, gc.LastPayoutPeriod)
+		}
+	}
+
+	statementKey, err := ctx.GetStub().CreateCompositeKey(payoutStatementObjectType, []string{technicianID, period})
+	if err != nil {
+		return nil, err
+	}
+	statementJSON, err := ctx.GetStub().GetState(statementKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if statementJSON != nil {
+		return nil, fmt.Errorf("payout statement for %s in period %s already exists", technicianID, period)
+	}
+
+	jobs, err := getJobs(ctx, technicianID)
+	if err != nil {
+		return nil, err
+	}
+	statement := PayoutStatement{
+		TechnicianID: technicianID,
+		Period:       period,
+		Amount:       gc.CarriedOverBalance,
+		Jobs:         []string{},
+		ClosedAt:     txTimestamp.AsTime(),
+	}
+	for _, job := range unbilledJobs(jobs) {
+		if !jobCompletedAt(job).Before(periodEnd) {
+			continue
+		}
+		statement.Amount += job.Payout
+		statement.Jobs = append(statement.Jobs, job.ID)
+		job.PayoutPeriod = period
+		err = putJob(ctx, technicianID, job)
+		if err != nil {
+			return nil, err
+		}
+	}
+	statementJSON, err = json.Marshal(statement)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(statementKey, statementJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	gc.CarriedOverBalance = 0
+	gc.LastPayoutPeriod = period
+	err = putGeneralContract(ctx, gc)
+	if err != nil {
+		return nil, err
+	}
+
+	err = events.Emit(ctx.GetStub(), events.PayoutPeriodClosed{
+		Version:      events.Version,
+		TechnicianID: technicianID,
+		Period:       period,
+		Amount:       statement.Amount,
+		Jobs:         statement.Jobs,
+		ClosedAt:     statement.ClosedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// jobCompletedAt returns when a job reached its final status, its CompletedAt or else the time of its
+// last transition, for example its cancellation.
+func jobCompletedAt(job *Job) time.Time {
+	if job.CompletedAt != nil {
+		return *job.CompletedAt
+	}
+	if len(job.Transitions) > 0 {
+		return job.Transitions[len(job.Transitions)-1].At
+	}
+	return job.Deadline
+}
+
+// ReadPayoutStatement returns the payout statement of a technician for the given period.
+func (s *SmartContract) ReadPayoutStatement(ctx contractapi.TransactionContextInterface, technicianID string, period string) (*PayoutStatement, error) {
+	_, err := authorizeRead(ctx, technicianID)
+	if err != nil {
+		return nil, err
+	}
+
+	statementKey, err := ctx.GetStub().CreateCompositeKey(payoutStatementObjectType, []string{technicianID, period})
+	if err != nil {
+		return nil, err
+	}
+	statementJSON, err := ctx.GetStub().GetState(statementKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if statementJSON == nil {
+		return nil, fmt.Errorf("there is no payout statement for %s in period %s", technicianID, period)
+	}
+
+	var statement PayoutStatement
+	err = json.Unmarshal(statementJSON, &statement)
+	if err != nil {
+		return nil, err
+	}
+
+	return &statement, nil
+}
+
+// GetPayoutStatements returns every closed payout statement of a technician, oldest period first.
+func (s *SmartContract) GetPayoutStatements(ctx contractapi.TransactionContextInterface, technicianID string) ([]*PayoutStatement, error) {
+	_, err := authorizeRead(ctx, technicianID)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(payoutStatementObjectType, []string{technicianID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	statements := []*PayoutStatement{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var statement PayoutStatement
+		err = json.Unmarshal(queryResponse.Value, &statement)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, &statement)
+	}
+
+	return statements, nil
+}
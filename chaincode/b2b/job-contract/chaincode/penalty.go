@@ -0,0 +1,181 @@
+package gc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const penaltyScheduleObjectType = "PenaltySchedule"
+
+// PenaltySchedule decides how much of the pay for a job of a service level is withheld when the
+// job is completed after its deadline. Every started day late costs PercentPerDay percent of the
+// pay, up to MaxPercent, after a grace period of GraceHours.
+type PenaltySchedule struct {
+	ServiceLevel  string `json:"ServiceLevel"`
+	PercentPerDay int    `json:"PercentPerDay"`
+	MaxPercent    int    `json:"MaxPercent"`
+	GraceHours    int    `json:"GraceHours"`
+}
+
+// defaultPenaltySchedules are used for service levels the owner has not configured.
+var defaultPenaltySchedules = map[string]PenaltySchedule{
+	"standard": {ServiceLevel: "standard", PercentPerDay: 5, MaxPercent: 50, GraceHours: 0},
+	"gold":     {ServiceLevel: "gold", PercentPerDay: 10, MaxPercent: 60, GraceHours: 0},
+	"platinum": {ServiceLevel: "platinum", PercentPerDay: 15, MaxPercent: 75, GraceHours: 0},
+}
+
+// SetPenaltySchedule configures the late-completion penalty for a service level.
+func (s *SmartContract) SetPenaltySchedule(ctx contractapi.TransactionContextInterface, serviceLevel string, percentPerDay int, maxPercent int, graceHours int) (*PenaltySchedule, error) {
+	_, err := requireRole(ctx, roleOwnerAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if serviceLevel == "" {
+		return nil, fmt.Errorf("service level must not be empty")
+	}
+	if percentPerDay < 0 || percentPerDay > 100 {
+		return nil, fmt.Errorf("percent per day must be between 0 and 100, got %d", percentPerDay)
+	}
+	if maxPercent < 0 || maxPercent > 100 {
+		return nil, fmt.Errorf("max percent must be between 0 and 100, got %d", maxPercent)
+	}
+	if graceHours < 0 {
+		return nil, fmt.Errorf("grace hours must not be negative, got %d", graceHours)
+	}
+
+	schedule := PenaltySchedule{
+		ServiceLevel:  serviceLevel,
+		PercentPerDay: percentPerDay,
+		MaxPercent:    maxPercent,
+		GraceHours:    graceHours,
+	}
+	scheduleKey, err := ctx.GetStub().CreateCompositeKey(penaltyScheduleObjectType, []string{serviceLevel})
+	if err != nil {
+		return nil, err
+	}
+	scheduleJSON, err := json.Marshal(schedule)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(scheduleKey, scheduleJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	return &schedule, nil
+}
+
+// ReadPenaltySchedule returns the late-completion penalty in force for a service level.
+func (s *SmartContract) ReadPenaltySchedule(ctx contractapi.TransactionContextInterface, serviceLevel string) (*PenaltySchedule, error) {
+	return readPenaltySchedule(ctx, serviceLevel)
+}
+
+func readPenaltySchedule(ctx contractapi.TransactionContextInterface, serviceLevel string) (*PenaltySchedule, error) {
+	scheduleKey, err := ctx.GetStub().CreateCompositeKey(penaltyScheduleObjectType, []string{serviceLevel})
+	if err != nil {
+		return nil, err
+	}
+	scheduleJSON, err := ctx.GetStub().GetState(scheduleKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if scheduleJSON == nil {
+		schedule, ok := defaultPenaltySchedules[serviceLevel]
+		if !ok {
+			return nil, fmt.Errorf("there is no penalty schedule for service level %s", serviceLevel)
+		}
+		return &schedule, nil
+	}
+
+	var schedule PenaltySchedule
+	err = json.Unmarshal(scheduleJSON, &schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// MarkOverdueJobs flags every open job of a technician whose deadline has passed and returns the newly flagged jobs.
+func (s *SmartContract) MarkOverdueJobs(ctx contractapi.TransactionContextInterface, technicianID string) ([]*Job, error) {
+	_, err := requireRole(ctx, roleOwnerAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := getJobs(ctx, technicianID)
+	if err != nil {
+		return nil, err
+	}
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	now := txTimestamp.AsTime()
+
+	overdueJobs := []*Job{}
+	for _, job := range jobs {
+		// a job awaiting inspection was completed when it was submitted, the inspection decides if it was late
+		if job.Overdue || isTerminalJobStatus(job.Status) || job.Status == JobStatusAwaitingInspection || !now.After(job.Deadline) {
+			continue
+		}
+		job.Overdue = true
+		err = putJob(ctx, technicianID, job)
+		if err != nil {
+			return nil, err
+		}
+		overdueJobs = append(overdueJobs, job)
+	}
+
+	return overdueJobs, nil
+}
+
+// completeJob stamps a job with its completion time, the late penalty, if any, on pay and what
+// should be paid out for the job.
+func completeJob(ctx contractapi.TransactionContextInterface, job *Job, pay int, completedAt time.Time) error {
+	serviceLevel := job.ServiceLevel
+	if serviceLevel == "" {
+		// jobs taken before the service level was recorded were all standard
+		serviceLevel = "standard"
+	}
+	schedule, err := readPenaltySchedule(ctx, serviceLevel)
+	if err != nil {
+		return err
+	}
+
+	daysLate, penalty := latePenalty(schedule, job.Deadline, completedAt, pay)
+	job.CompletedAt = &completedAt
+	job.DaysLate = daysLate
+	job.Penalty = penalty
+	job.Payout = pay - penalty
+	if daysLate > 0 {
+		job.Overdue = true
+	}
+
+	return nil
+}
+
+// latePenalty returns how many started days after the deadline (and grace period) a job was
+// completed, and how much of pay is withheld for it.
+func latePenalty(schedule *PenaltySchedule, deadline time.Time, completedAt time.Time, pay int) (int, int) {
+	if deadline.IsZero() {
+		return 0, 0
+	}
+
+	lateBy := completedAt.Sub(deadline) - time.Duration(schedule.GraceHours)*time.Hour
+	if lateBy <= 0 {
+		return 0, 0
+	}
+
+	daysLate := int((lateBy + 24*time.Hour - 1) / (24 * time.Hour))
+	percent := daysLate * schedule.PercentPerDay
+	if percent > schedule.MaxPercent {
+		percent = schedule.MaxPercent
+	}
+
+	return daysLate, pay * percent / 100
+}
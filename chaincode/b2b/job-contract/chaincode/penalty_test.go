@@ -0,0 +1,42 @@
+package gc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatePenalty(t *testing.T) {
+	deadline := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	gold := defaultPenaltySchedules["gold"]
+	withGrace := PenaltySchedule{ServiceLevel: "gold", PercentPerDay: 10, MaxPercent: 60, GraceHours: 12}
+
+	tests := []struct {
+		name         string
+		schedule     PenaltySchedule
+		deadline     time.Time
+		completedAt  time.Time
+		pay          int
+		wantDaysLate int
+		wantPenalty  int
+	}{
+		{"before the deadline", gold, deadline, deadline.Add(-time.Hour), 100, 0, 0},
+		{"at the deadline", gold, deadline, deadline, 100, 0, 0},
+		{"a second late is a started day", gold, deadline, deadline.Add(time.Second), 100, 1, 10},
+		{"exactly one day late", gold, deadline, deadline.Add(24 * time.Hour), 100, 1, 10},
+		{"just over one day late", gold, deadline, deadline.Add(24*time.Hour + time.Second), 100, 2, 20},
+		{"capped at the max percent", gold, deadline, deadline.Add(30 * 24 * time.Hour), 100, 30, 60},
+		{"within the grace period", withGrace, deadline, deadline.Add(12 * time.Hour), 100, 0, 0},
+		{"after the grace period", withGrace, deadline, deadline.Add(12*time.Hour + time.Second), 100, 1, 10},
+		{"penalty is rounded down", gold, deadline, deadline.Add(time.Hour), 75, 1, 7},
+		{"no deadline", gold, time.Time{}, deadline, 100, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			daysLate, penalty := latePenalty(&tt.schedule, tt.deadline, tt.completedAt, tt.pay)
+			if daysLate != tt.wantDaysLate || penalty != tt.wantPenalty {
+				t.Errorf("latePenalty() = %d days, %d, want %d days, %d", daysLate, penalty, tt.wantDaysLate, tt.wantPenalty)
+			}
+		})
+	}
+}
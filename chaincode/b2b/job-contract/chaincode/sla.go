@@ -0,0 +1,80 @@
+package gc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// slaChaincodeName and slaChannelName are where the customer SLAs are kept.
+	slaChaincodeName = "mower"
+	slaChannelName   = "customer"
+	// mowerRegistryChaincodeName is where the mowers of the customers are registered, on slaChannelName.
+	mowerRegistryChaincodeName = "mower-registry"
+)
+
+var serviceLevels = []string{"standard", "gold", "platinum"}
+
+// customerSLA is the part of an SLA in the mower chaincode that jobs depend on.
+type customerSLA struct {
+	ID           string `json:"ID"`
+	ServiceLevel string `json:"ServiceLevel"`
+	MowerSerial  string `json:"MowerSerial,omitempty"`
+	// Status is empty for SLAs from before they had a lifecycle.
+	Status string `json:"Status,omitempty"`
+}
+
+// validateWorkOrderSLA checks the SLA a work order refers to and returns its service level. The mower of
+// the work order must be registered and active in the mower registry, and must be the mower the SLA covers.
+// SLAs from before the registry cover no mower. A suspended or terminated SLA gets no jobs, and a level
+// attested by the oracle must be the level of the SLA.
+func validateWorkOrderSLA(ctx contractapi.TransactionContextInterface, workOrder *WorkOrder) (string, error) {
+	if workOrder.SLAID == "" {
+		return "", fmt.Errorf("work order %s does not refer to an SLA", workOrder.WorkID)
+	}
+
+	invokeArgs := [][]byte{[]byte("ValidateMower"), []byte(workOrder.ProductID), []byte("")}
+	response := ctx.GetStub().InvokeChaincode(mowerRegistryChaincodeName, invokeArgs, slaChannelName)
+	if response.Status != shim.OK {
+		return "", fmt.Errorf("work order %s is for mower %s which can not get jobs: %s", workOrder.WorkID, workOrder.ProductID, response.Message)
+	}
+
+	sla, err := readCustomerSLA(ctx, workOrder.SLAID)
+	if err != nil {
+		return "", err
+	}
+	if sla.Status == "Suspended" || sla.Status == "Terminated" {
+		return "", fmt.Errorf("SLA %s is %s and can not get jobs", workOrder.SLAID, sla.Status)
+	}
+	if sla.MowerSerial != "" && sla.MowerSerial != workOrder.ProductID {
+		return "", fmt.Errorf("SLA %s covers mower %s, not mower %s", workOrder.SLAID, sla.MowerSerial, workOrder.ProductID)
+	}
+	if workOrder.ServiceLevel != "" && workOrder.ServiceLevel != sla.ServiceLevel {
+		return "", fmt.Errorf("work order %s attests service level %s, but SLA %s has service level %s", workOrder.WorkID, workOrder.ServiceLevel, workOrder.SLAID, sla.ServiceLevel)
+	}
+
+	if !containsString(serviceLevels, sla.ServiceLevel) {
+		return "", fmt.Errorf("SLA %s has unknown service level %s", workOrder.SLAID, sla.ServiceLevel)
+	}
+	return sla.ServiceLevel, nil
+}
+
+// readCustomerSLA reads an SLA from the mower chaincode on the customer channel.
+func readCustomerSLA(ctx contractapi.TransactionContextInterface, slaID string) (*customerSLA, error) {
+	// a query on another channel is read only and is not part of the read set of this transaction
+	invokeArgs := [][]byte{[]byte("ReadSLA"), []byte(slaID)}
+	response := ctx.GetStub().InvokeChaincode(slaChaincodeName, invokeArgs, slaChannelName)
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to read SLA %s from %s on channel %s: %s", slaID, slaChaincodeName, slaChannelName, response.Message)
+	}
+
+	var sla customerSLA
+	err := json.Unmarshal(response.Payload, &sla)
+	if err != nil {
+		return nil, err
+	}
+	return &sla, nil
+}
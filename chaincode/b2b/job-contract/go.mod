@@ -5,8 +5,7 @@ go 1.22.0
 require (
 	github.com/hyperledger/fabric-chaincode-go v0.0.0-20230731094759-d626e9ab09b9
 	github.com/hyperledger/fabric-contract-api-go v1.2.2
-	github.com/nalle631/arrowheadfunctions v1.5.2
-
+	github.com/nalle631/fabric-network/chaincode/events v0.0.0
 )
 
 require (
@@ -35,3 +34,5 @@ require (
 	google.golang.org/protobuf v1.31.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/nalle631/fabric-network/chaincode/events => ../../events
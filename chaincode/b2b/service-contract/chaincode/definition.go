@@ -0,0 +1,160 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// serviceDefinitionKey is the config key the service definition of the deployment is stored under.
+	serviceDefinitionKey = "ServiceDefinition"
+
+	// ownerMSPID is the organisation that owns the services, only its owner-admin may configure them.
+	ownerMSPID     = "Org2MSP"
+	roleAttribute  = "role"
+	roleOwnerAdmin = "owner-admin"
+
+	// VerificationInspection means an inspector has to approve the work before the job is paid.
+	VerificationInspection = "inspection"
+	// VerificationSelfReported means the job is paid on the technician's own report that it is done.
+	VerificationSelfReported = "self-reported"
+)
+
+// ServiceDefinition configures what service a deployment of the service chaincode provides.
+// The same chaincode is deployed once per service under its own name, each with its own definition.
+// JobPay and InspectionPay are the rates the service was initialized with, later rates are set with SetRates.
+type ServiceDefinition struct {
+	Type          string `json:"Type"`
+	JobPay        int    `json:"JobPay"`
+	InspectionPay int    `json:"InspectionPay"`
+	// RequiredAuthority is the job authority a technician needs to take jobs of the service.
+	// When it is empty the event type of the job is used.
+	RequiredAuthority string `json:"RequiredAuthority,omitempty"`
+	VerificationMode  string `json:"VerificationMode"`
+}
+
+// InitService configures the deployment. It is called once after the chaincode is deployed.
+func (s *SmartContract) InitService(ctx contractapi.TransactionContextInterface, serviceType string, jobPay int, inspectionPay int, requiredAuthority string, verificationMode string) (*ServiceDefinition, error) {
+	err := requireOwnerAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	definitionJSON, err := ctx.GetStub().GetState(serviceDefinitionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if definitionJSON != nil {
+		return nil, fmt.Errorf("service is already initialized, use UpdateServiceDefinition to change it")
+	}
+
+	definition := ServiceDefinition{
+		Type:              serviceType,
+		JobPay:            jobPay,
+		InspectionPay:     inspectionPay,
+		RequiredAuthority: requiredAuthority,
+		VerificationMode:  verificationMode,
+	}
+	err = putServiceDefinition(ctx, &definition)
+	if err != nil {
+		return nil, err
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	_, err = addRateCard(ctx, jobPay, inspectionPay, txTimestamp.AsTime())
+	if err != nil {
+		return nil, err
+	}
+
+	return &definition, nil
+}
+
+// UpdateServiceDefinition changes the type, required authority and verification mode of the deployment.
+// The pay is changed with SetRates.
+func (s *SmartContract) UpdateServiceDefinition(ctx contractapi.TransactionContextInterface, serviceType string, requiredAuthority string, verificationMode string) (*ServiceDefinition, error) {
+	err := requireOwnerAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	definition, err := readServiceDefinition(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	definition.Type = serviceType
+	definition.RequiredAuthority = requiredAuthority
+	definition.VerificationMode = verificationMode
+	err = putServiceDefinition(ctx, definition)
+	if err != nil {
+		return nil, err
+	}
+
+	return definition, nil
+}
+
+// ReadServiceDefinition returns the definition of the deployment.
+func (s *SmartContract) ReadServiceDefinition(ctx contractapi.TransactionContextInterface) (*ServiceDefinition, error) {
+	return readServiceDefinition(ctx)
+}
+
+func readServiceDefinition(ctx contractapi.TransactionContextInterface) (*ServiceDefinition, error) {
+	definitionJSON, err := ctx.GetStub().GetState(serviceDefinitionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if definitionJSON == nil {
+		return nil, fmt.Errorf("service is not initialized, call InitService first")
+	}
+
+	var definition ServiceDefinition
+	err = json.Unmarshal(definitionJSON, &definition)
+	if err != nil {
+		return nil, err
+	}
+
+	return &definition, nil
+}
+
+func putServiceDefinition(ctx contractapi.TransactionContextInterface, definition *ServiceDefinition) error {
+	if definition.Type == "" {
+		return fmt.Errorf("service type must not be empty")
+	}
+	if definition.JobPay < 0 || definition.InspectionPay < 0 {
+		return fmt.Errorf("pay must not be negative")
+	}
+	if definition.VerificationMode != VerificationInspection && definition.VerificationMode != VerificationSelfReported {
+		return fmt.Errorf("verification mode must be %s or %s, got %s", VerificationInspection, VerificationSelfReported, definition.VerificationMode)
+	}
+
+	definitionJSON, err := json.Marshal(definition)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(serviceDefinitionKey, definitionJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+	return nil
+}
+
+// requireOwnerAdmin fails unless the caller is an owner-admin of the owner organisation.
+func requireOwnerAdmin(ctx contractapi.TransactionContextInterface) error {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	role, _, err := ctx.GetClientIdentity().GetAttributeValue(roleAttribute)
+	if err != nil {
+		return fmt.Errorf("failed to get client role: %v", err)
+	}
+	if mspID != ownerMSPID || role != roleOwnerAdmin {
+		return fmt.Errorf("only the %s of %s may configure the service", roleOwnerAdmin, ownerMSPID)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// jobContractName is the name the job contract is deployed under. Jobs are only changed when the job
+// contract invokes the service chaincode, so that the two always agree on the status of a job.
+const jobContractName = "gc"
+
+// The service chaincode keeps the coarse status of a job. EnRoute is still Assigned and
+// AwaitingInspection is still InProgress here, the job contract has the detailed status.
+const (
+	JobStatusAssigned   = "Assigned"
+	JobStatusInProgress = "InProgress"
+	JobStatusDone       = "Done"
+	JobStatusFailed     = "Failed"
+	JobStatusCancelled  = "Cancelled"
+	JobStatusExpired    = "Expired"
+)
+
+// Start marks that the technician has started working on the job.
+func (s *SmartContract) Start(ctx contractapi.TransactionContextInterface, jobID string) (*Job, error) {
+	return setJobStatus(ctx, jobID, JobStatusInProgress, JobStatusAssigned)
+}
+
+// Complete marks that the job is done.
+func (s *SmartContract) Complete(ctx contractapi.TransactionContextInterface, jobID string) (*Job, error) {
+	return setJobStatus(ctx, jobID, JobStatusDone, JobStatusInProgress)
+}
+
+// Fail marks that the technician could not complete the job.
+func (s *SmartContract) Fail(ctx contractapi.TransactionContextInterface, jobID string) (*Job, error) {
+	return setJobStatus(ctx, jobID, JobStatusFailed, JobStatusAssigned, JobStatusInProgress)
+}
+
+// Cancel marks that the owner cancelled the job.
+func (s *SmartContract) Cancel(ctx contractapi.TransactionContextInterface, jobID string) (*Job, error) {
+	return setJobStatus(ctx, jobID, JobStatusCancelled, JobStatusAssigned, JobStatusInProgress)
+}
+
+// Expire marks that the owner expired the job.
+func (s *SmartContract) Expire(ctx contractapi.TransactionContextInterface, jobID string) (*Job, error) {
+	return setJobStatus(ctx, jobID, JobStatusExpired, JobStatusAssigned, JobStatusInProgress)
+}
+
+// Reassign puts the job back to Assigned when the owner gives it to another technician.
+func (s *SmartContract) Reassign(ctx contractapi.TransactionContextInterface, jobID string) (*Job, error) {
+	return setJobStatus(ctx, jobID, JobStatusAssigned, JobStatusAssigned, JobStatusInProgress)
+}
+
+// setJobStatus moves a job to status if its current status is one of from.
+func setJobStatus(ctx contractapi.TransactionContextInterface, jobID string, status string, from ...string) (*Job, error) {
+	err := requireJobContract(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := readJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsString(from, job.Status) {
+		return nil, fmt.Errorf("job %s can not move from %s to %s", jobID, job.Status, status)
+	}
+
+	previous := *job
+	job.Status = status
+	err = putJob(ctx, job, &previous)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func readJob(ctx contractapi.TransactionContextInterface, jobID string) (*Job, error) {
+	jobJSON, err := ctx.GetStub().GetState(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if jobJSON == nil {
+		return nil, fmt.Errorf("Job %s does not exist on ledger", jobID)
+	}
+
+	var job Job
+	err = json.Unmarshal(jobJSON, &job)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// requireJobContract fails unless the transaction was sent to the job contract, which then invoked
+// this chaincode. A transaction sent straight to the service chaincode can not change its jobs.
+func requireJobContract(ctx contractapi.TransactionContextInterface) error {
+	signedProposal, err := ctx.GetStub().GetSignedProposal()
+	if err != nil {
+		return err
+	}
+
+	var proposal peer.Proposal
+	err = proto.Unmarshal(signedProposal.ProposalBytes, &proposal)
+	if err != nil {
+		return fmt.Errorf("failed to read the proposal: %v", err)
+	}
+	var payload peer.ChaincodeProposalPayload
+	err = proto.Unmarshal(proposal.Payload, &payload)
+	if err != nil {
+		return fmt.Errorf("failed to read the proposal payload: %v", err)
+	}
+	var invocationSpec peer.ChaincodeInvocationSpec
+	err = proto.Unmarshal(payload.Input, &invocationSpec)
+	if err != nil {
+		return fmt.Errorf("failed to read the invoked chaincode: %v", err)
+	}
+
+	invoked := invocationSpec.GetChaincodeSpec().GetChaincodeId().GetName()
+	if invoked != jobContractName {
+		return fmt.Errorf("jobs can only be changed through the %s chaincode, the transaction was sent to %s", jobContractName, invoked)
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Jobs are queried from composite-key indexes that are kept next to every job, which works on both
+// LevelDB and CouchDB. When the chaincode is started with RichQueries, the peers use CouchDB and the
+// same queries are CouchDB selector queries, using the indexes in META-INF/statedb/couchdb/indexes.
+const (
+	jobDocType    = "job"
+	mowerIndex    = "mower~id"
+	statusIndex   = "status~id"
+	addressIndex  = "address~id"
+	deadlineIndex = "day~deadline~id"
+
+	// deadlineLayout is how Deadline is marshalled in the stored jobs, deadlines are UTC with whole seconds.
+	deadlineLayout = "2006-01-02T15:04:05Z"
+
+	// maxDeadlineRangeDays limits GetJobsByDeadlineRange, the composite-key index is read one day at a time.
+	maxDeadlineRangeDays = 366
+)
+
+// GetJobsByMower returns every job of a mower.
+func (s *SmartContract) GetJobsByMower(ctx contractapi.TransactionContextInterface, mower string) ([]*Job, error) {
+	selector := map[string]interface{}{"docType": jobDocType, "Mower": mower}
+	return queryJobs(ctx, s.RichQueries, selector, "indexMower", func() ([]*Job, error) {
+		return getJobsByIndex(ctx, mowerIndex, []string{mower})
+	})
+}
+
+// GetJobsByStatus returns every job with a status, for example every Assigned job.
+func (s *SmartContract) GetJobsByStatus(ctx contractapi.TransactionContextInterface, status string) ([]*Job, error) {
+	selector := map[string]interface{}{"docType": jobDocType, "Status": status}
+	return queryJobs(ctx, s.RichQueries, selector, "indexStatus", func() ([]*Job, error) {
+		return getJobsByIndex(ctx, statusIndex, []string{status})
+	})
+}
+
+// GetJobsByAddress returns every job at an address.
+func (s *SmartContract) GetJobsByAddress(ctx contractapi.TransactionContextInterface, address string) ([]*Job, error) {
+	selector := map[string]interface{}{"docType": jobDocType, "Address": address}
+	return queryJobs(ctx, s.RichQueries, selector, "indexAddress", func() ([]*Job, error) {
+		return getJobsByIndex(ctx, addressIndex, []string{address})
+	})
+}
+
+// GetJobsByDeadlineRange returns the jobs with a deadline between from and to, both RFC 3339 times and
+// included. When statuses is not empty only jobs with one of those statuses are returned, for example
+// ["Assigned","InProgress"] for the open jobs due this week.
+func (s *SmartContract) GetJobsByDeadlineRange(ctx contractapi.TransactionContextInterface, from string, to string, statuses []string) ([]*Job, error) {
+	fromTime, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return nil, fmt.Errorf("from must be an RFC 3339 time: %v", err)
+	}
+	toTime, err := time.Parse(time.RFC3339, to)
+	if err != nil {
+		return nil, fmt.Errorf("to must be an RFC 3339 time: %v", err)
+	}
+	fromTime, toTime = fromTime.UTC(), toTime.UTC()
+	if toTime.Before(fromTime) {
+		return nil, fmt.Errorf("to %s is before from %s", to, from)
+	}
+	if toTime.Sub(fromTime) > maxDeadlineRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("deadline range can be at most %d days", maxDeadlineRangeDays)
+	}
+
+	// deadlines have whole seconds, so the range is narrowed to whole seconds before it is compared as text
+	if truncated := fromTime.Truncate(time.Second); truncated.Before(fromTime) {
+		fromTime = truncated.Add(time.Second)
+	}
+	toTime = toTime.Truncate(time.Second)
+
+	selector := map[string]interface{}{
+		"docType":  jobDocType,
+		"Deadline": map[string]interface{}{"$gte": fromTime.Format(deadlineLayout), "$lte": toTime.Format(deadlineLayout)},
+	}
+	if len(statuses) > 0 {
+		selector["Status"] = map[string]interface{}{"$in": statuses}
+	}
+
+	return queryJobs(ctx, s.RichQueries, selector, "indexDeadline", func() ([]*Job, error) {
+		jobs := []*Job{}
+		for day := fromTime.Truncate(24 * time.Hour); !day.After(toTime); day = day.AddDate(0, 0, 1) {
+			dayJobs, err := getJobsByIndex(ctx, deadlineIndex, []string{day.Format("2006-01-02")})
+			if err != nil {
+				return nil, err
+			}
+			for _, job := range dayJobs {
+				if job.Deadline.Before(fromTime) || job.Deadline.After(toTime) {
+					continue
+				}
+				if len(statuses) > 0 && !containsString(statuses, job.Status) {
+					continue
+				}
+				jobs = append(jobs, job)
+			}
+		}
+		return jobs, nil
+	})
+}
+
+// ReindexJobs adds the docType and the composite-key indexes to jobs created before jobs could be queried.
+func (s *SmartContract) ReindexJobs(ctx contractapi.TransactionContextInterface) (int, error) {
+	err := requireOwnerAdmin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// jobs are stored under their job ID, every other record of the chaincode is a config or composite key
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	reindexed := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+		var job Job
+		err = json.Unmarshal(queryResponse.Value, &job)
+		if err != nil || job.ID != queryResponse.Key || job.DocType == jobDocType {
+			continue
+		}
+
+		err = putJob(ctx, &job, nil)
+		if err != nil {
+			return 0, err
+		}
+		reindexed++
+	}
+
+	return reindexed, nil
+}
+
+// putJob stores a job and its composite-key indexes. previous is the job as it was stored before,
+// its index entries are removed, and nil for a new job.
+func putJob(ctx contractapi.TransactionContextInterface, job *Job, previous *Job) error {
+	job.DocType = jobDocType
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(job.ID, jobJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	if previous != nil {
+		err = deleteJobIndexes(ctx, previous)
+		if err != nil {
+			return err
+		}
+	}
+	indexKeys, err := jobIndexKeys(ctx, job)
+	if err != nil {
+		return err
+	}
+	for _, indexKey := range indexKeys {
+		// the value of an index entry is not used, the job is read by its ID
+		err = ctx.GetStub().PutState(indexKey, []byte{0x00})
+		if err != nil {
+			return fmt.Errorf("failed to put to world state. %v", err)
+		}
+	}
+	return nil
+}
+
+func deleteJobIndexes(ctx contractapi.TransactionContextInterface, job *Job) error {
+	indexKeys, err := jobIndexKeys(ctx, job)
+	if err != nil {
+		return err
+	}
+	for _, indexKey := range indexKeys {
+		err = ctx.GetStub().DelState(indexKey)
+		if err != nil {
+			return fmt.Errorf("failed to delete from world state. %v", err)
+		}
+	}
+	return nil
+}
+
+func jobIndexKeys(ctx contractapi.TransactionContextInterface, job *Job) ([]string, error) {
+	deadline := job.Deadline.UTC()
+	indexes := map[string][]string{
+		mowerIndex:    {job.Mower, job.ID},
+		statusIndex:   {job.Status, job.ID},
+		addressIndex:  {job.Address, job.ID},
+		deadlineIndex: {deadline.Format("2006-01-02"), deadline.Format(time.RFC3339), job.ID},
+	}
+
+	indexKeys := []string{}
+	for _, indexName := range []string{mowerIndex, statusIndex, addressIndex, deadlineIndex} {
+		indexKey, err := ctx.GetStub().CreateCompositeKey(indexName, indexes[indexName])
+		if err != nil {
+			return nil, err
+		}
+		indexKeys = append(indexKeys, indexKey)
+	}
+	return indexKeys, nil
+}
+
+// queryJobs runs a CouchDB selector query using the named index when richQueries is set, and reads
+// the jobs with indexQuery otherwise.
+func queryJobs(ctx contractapi.TransactionContextInterface, richQueries bool, selector map[string]interface{}, indexName string, indexQuery func() ([]*Job, error)) ([]*Job, error) {
+	if !richQueries {
+		return indexQuery()
+	}
+
+	query, err := json.Marshal(map[string]interface{}{
+		"selector":  selector,
+		"use_index": []string{"_design/" + indexName + "Doc", indexName},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(string(query))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	jobs := []*Job{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var job Job
+		err = json.Unmarshal(queryResponse.Value, &job)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, nil
+}
+
+// getJobsByIndex reads the jobs whose index entries in indexName start with attributes.
+func getJobsByIndex(ctx contractapi.TransactionContextInterface, indexName string, attributes []string) ([]*Job, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(indexName, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	jobs := []*Job{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		job, err := readJob(ctx, keyParts[len(keyParts)-1])
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
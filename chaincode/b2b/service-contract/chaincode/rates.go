@@ -0,0 +1,178 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// rateCardObjectType is the composite key prefix of the rate card versions, keyed by the zero padded
+// version so that the versions are iterated in order.
+const rateCardObjectType = "RateCard"
+
+// RateCard is one version of the pay for jobs of the service. A job is paid by the version with the
+// latest EffectiveFrom that is not after the time the job is created.
+type RateCard struct {
+	Version       int       `json:"Version"`
+	JobPay        int       `json:"JobPay"`
+	InspectionPay int       `json:"InspectionPay"`
+	EffectiveFrom time.Time `json:"EffectiveFrom"`
+	SetAt         time.Time `json:"SetAt"`
+	SetBy         string    `json:"SetBy"`
+}
+
+// Rates is the rate card in force and the versions that will come into force later.
+type Rates struct {
+	Current  *RateCard   `json:"Current"`
+	Upcoming []*RateCard `json:"Upcoming"`
+}
+
+// SetRates adds a new version of the rate card. effectiveFrom is an RFC 3339 time that must not be in the past.
+func (s *SmartContract) SetRates(ctx contractapi.TransactionContextInterface, jobPay int, inspectionPay int, effectiveFrom string) (*RateCard, error) {
+	err := requireOwnerAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	now := txTimestamp.AsTime()
+
+	effectiveFromTime, err := time.Parse(time.RFC3339, effectiveFrom)
+	if err != nil {
+		return nil, fmt.Errorf("effective from must be an RFC 3339 time: %v", err)
+	}
+	if effectiveFromTime.Before(now) {
+		return nil, fmt.Errorf("rates can not take effect in the past, %s is before %s", effectiveFrom, now.Format(time.RFC3339))
+	}
+
+	return addRateCard(ctx, jobPay, inspectionPay, effectiveFromTime)
+}
+
+// GetRates returns the rate card in force at the time of the transaction and the upcoming versions.
+func (s *SmartContract) GetRates(ctx contractapi.TransactionContextInterface) (*Rates, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	now := txTimestamp.AsTime()
+
+	rateCards, err := getRateCards(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := Rates{Upcoming: []*RateCard{}}
+	for _, rateCard := range rateCards {
+		if rateCard.EffectiveFrom.After(now) {
+			rates.Upcoming = append(rates.Upcoming, rateCard)
+		} else if rates.Current == nil || !rateCard.EffectiveFrom.Before(rates.Current.EffectiveFrom) {
+			rates.Current = rateCard
+		}
+	}
+
+	return &rates, nil
+}
+
+// GetRateHistory returns every version of the rate card.
+func (s *SmartContract) GetRateHistory(ctx contractapi.TransactionContextInterface) ([]*RateCard, error) {
+	return getRateCards(ctx)
+}
+
+// rateInForce returns the rate card in force at t. Deployments initialized before there were rate
+// cards are paid by the service definition, as version 0.
+func rateInForce(ctx contractapi.TransactionContextInterface, definition *ServiceDefinition, t time.Time) (*RateCard, error) {
+	rateCards, err := getRateCards(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var inForce *RateCard
+	for _, rateCard := range rateCards {
+		if rateCard.EffectiveFrom.After(t) {
+			continue
+		}
+		if inForce == nil || !rateCard.EffectiveFrom.Before(inForce.EffectiveFrom) {
+			inForce = rateCard
+		}
+	}
+	if inForce == nil {
+		if len(rateCards) > 0 {
+			return nil, fmt.Errorf("no rates are in force at %s", t.Format(time.RFC3339))
+		}
+		return &RateCard{JobPay: definition.JobPay, InspectionPay: definition.InspectionPay}, nil
+	}
+
+	return inForce, nil
+}
+
+func addRateCard(ctx contractapi.TransactionContextInterface, jobPay int, inspectionPay int, effectiveFrom time.Time) (*RateCard, error) {
+	if jobPay < 0 || inspectionPay < 0 {
+		return nil, fmt.Errorf("pay must not be negative")
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	setBy, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client ID: %v", err)
+	}
+	rateCards, err := getRateCards(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rateCard := RateCard{
+		Version:       len(rateCards) + 1,
+		JobPay:        jobPay,
+		InspectionPay: inspectionPay,
+		EffectiveFrom: effectiveFrom,
+		SetAt:         txTimestamp.AsTime(),
+		SetBy:         setBy,
+	}
+	rateCardKey, err := ctx.GetStub().CreateCompositeKey(rateCardObjectType, []string{fmt.Sprintf("%08d", rateCard.Version)})
+	if err != nil {
+		return nil, err
+	}
+	rateCardJSON, err := json.Marshal(rateCard)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(rateCardKey, rateCardJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	return &rateCard, nil
+}
+
+func getRateCards(ctx contractapi.TransactionContextInterface) ([]*RateCard, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(rateCardObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	rateCards := []*RateCard{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var rateCard RateCard
+		err = json.Unmarshal(queryResponse.Value, &rateCard)
+		if err != nil {
+			return nil, err
+		}
+		rateCards = append(rateCards, &rateCard)
+	}
+
+	return rateCards, nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/nalle631/fabric-network/chaincode/events"
+)
+
+// SmartContract provides functions for managing the jobs of one service
+type SmartContract struct {
+	contractapi.Contract
+	// RichQueries makes the job queries CouchDB selector queries, it can only be set when every peer uses CouchDB.
+	RichQueries bool
+}
+
+// Asset describes basic details of what makes up a simple asset
+// Insert struct field in alphabetic order => to achieve determinism across languages
+// golang keeps the order when marshal to json but doesn't order automatically
+type Job struct {
+	Type          string    `json:"Type"`
+	Status        string    `json:"Status"`
+	JobPay        int       `json:"JobPay"`
+	InspectionPay int       `json:"InspectionPay"`
+	Deadline      time.Time `json:"Deadline,omitempty"`
+	ID            string    `json:"ID"`
+	Mower         string    `json:"Mower"`
+	Address       string    `json:"Address"`
+	RateVersion   int       `json:"RateVersion"`
+	DocType       string    `json:"docType"`
+}
+
+func (s *SmartContract) Create(ctx contractapi.TransactionContextInterface, technichianID string, jobID string, mower string, address string, deadline string) (*Job, error) {
+	err := requireJobContract(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	definition, err := readServiceDefinition(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobExistsOnLedger, err := s.JobExistsOnLedger(ctx, jobID)
+
+	fmt.Println("Mower: ", mower)
+
+	if err != nil {
+		fmt.Println("Error when checking if job exists on ledger: ", err)
+		return nil, err
+	}
+
+	if jobExistsOnLedger {
+		fmt.Println("Job already exists on ledger")
+		return nil, fmt.Errorf("Job %s already exists on ledger", jobID)
+	}
+
+	timeDeadline, err := time.Parse("2006-01-02 15:04:05", deadline)
+	if err != nil {
+		fmt.Println("Error parsing deadline: ", err)
+		return nil, err
+	}
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	rates, err := rateInForce(ctx, definition, txTimestamp.AsTime())
+	if err != nil {
+		return nil, err
+	}
+	job := Job{
+		Type:          definition.Type,
+		Status:        JobStatusAssigned,
+		JobPay:        rates.JobPay,
+		InspectionPay: rates.InspectionPay,
+		ID:            jobID,
+		Deadline:      timeDeadline,
+		Mower:         mower,
+		Address:       address,
+		RateVersion:   rates.Version,
+	}
+	err = putJob(ctx, &job, nil)
+	if err != nil {
+		fmt.Println("Error putting job to world state: ", err)
+		return nil, err
+	}
+
+	// Create is always called by the job contract, so the JobTaken event of the job contract is the one
+	// delivered to listeners, see package events
+	err = events.Emit(ctx.GetStub(), events.ServiceJobCreated{
+		Version:     events.Version,
+		Service:     definition.Type,
+		JobID:       job.ID,
+		Mower:       job.Mower,
+		Address:     job.Address,
+		Deadline:    job.Deadline,
+		RateVersion: job.RateVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// Release removes a job so that it can be created again when another technician takes it.
+func (s *SmartContract) Release(ctx contractapi.TransactionContextInterface, jobID string) error {
+	err := requireJobContract(ctx)
+	if err != nil {
+		return err
+	}
+
+	job, err := readJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	err = deleteJobIndexes(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().DelState(jobID)
+}
+
+func (s *SmartContract) JobExistsOnLedger(ctx contractapi.TransactionContextInterface, jobID string) (bool, error) {
+	jobJSON, err := ctx.GetStub().GetState(jobID)
+	fmt.Println("jobJSON: ", string(jobJSON))
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	if jobJSON == nil {
+		return false, nil
+	}
+
+	var job Job
+	err = json.Unmarshal(jobJSON, &job)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -1,10 +1,12 @@
-module github.com/nalle631/fabric-network/chaincode/b2b/battery-contract
+module github.com/nalle631/fabric-network/chaincode/b2b/service-contract
 
 go 1.22.0
 
 require (
+	github.com/golang/protobuf v1.5.3
 	github.com/hyperledger/fabric-contract-api-go v1.2.2
-	github.com/nalle631/arrowheadfunctions v1.5.2
+	github.com/hyperledger/fabric-protos-go v0.3.0
+	github.com/nalle631/fabric-network/chaincode/events v0.0.0
 )
 
 require (
@@ -15,9 +17,7 @@ require (
 	github.com/gobuffalo/envy v1.10.2 // indirect
 	github.com/gobuffalo/packd v1.0.2 // indirect
 	github.com/gobuffalo/packr v1.30.1 // indirect
-	github.com/golang/protobuf v1.5.3 // indirect
 	github.com/hyperledger/fabric-chaincode-go v0.0.0-20230731094759-d626e9ab09b9 // indirect
-	github.com/hyperledger/fabric-protos-go v0.3.0 // indirect
 	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
@@ -34,3 +34,5 @@ require (
 	google.golang.org/protobuf v1.31.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/nalle631/fabric-network/chaincode/events => ../../events
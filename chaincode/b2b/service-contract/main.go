@@ -0,0 +1,20 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	service "github.com/nalle631/fabric-network/chaincode/b2b/service-contract/chaincode"
+)
+
+func main() {
+	serviceChaincode, err := contractapi.NewChaincode(&service.SmartContract{RichQueries: os.Getenv("RICH_QUERIES") == "true"})
+	if err != nil {
+		log.Panicf("Error creating service chaincode: %v", err)
+	}
+
+	if err := serviceChaincode.Start(); err != nil {
+		log.Panicf("Error starting service chaincode: %v", err)
+	}
+}
@@ -0,0 +1,375 @@
+package customer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/nalle631/fabric-network/chaincode/events"
+)
+
+const (
+	// invoiceObjectType is the composite key prefix of invoices, keyed by customer and period.
+	invoiceObjectType = "Invoice"
+
+	// periodLayout is the layout of a billing period, a calendar month in UTC.
+	periodLayout = "2006-01"
+	// paymentTerms is how long after it is issued an invoice is due.
+	paymentTerms = 30 * 24 * time.Hour
+	// settlementPeriod is how long after its end a period can be invoiced. The invoice is read from the
+	// history of the customer contract, which is not checked again when the invoice is committed, so every
+	// change made during the period must have been committed by then.
+	settlementPeriod = 24 * time.Hour
+
+	InvoiceStatusOpen = "Open"
+	InvoiceStatusPaid = "Paid"
+)
+
+// Invoice bills a customer for the SLAs it had during a period. An invoice is overdue when it is still open
+// after its DueDate.
+type Invoice struct {
+	CustomerID  string            `json:"CustomerID"`
+	Period      string            `json:"Period"`
+	PeriodStart time.Time         `json:"PeriodStart"`
+	PeriodEnd   time.Time         `json:"PeriodEnd"`
+	LineItems   []InvoiceLineItem `json:"LineItems"`
+	Total       int               `json:"Total"`
+	IssuedAt    time.Time         `json:"IssuedAt"`
+	DueDate     time.Time         `json:"DueDate"`
+	Status      string            `json:"Status"`
+	PaidAt      *time.Time        `json:"PaidAt,omitempty"`
+}
+
+// InvoiceLineItem is the part of a period an SLA had the same service level and price. Amount is the
+// MonthlyCost prorated by the Days of the period the line item covers.
+type InvoiceLineItem struct {
+	SLAID        string    `json:"SLAID"`
+	ServiceLevel string    `json:"ServiceLevel"`
+	MonthlyCost  int       `json:"MonthlyCost"`
+	From         time.Time `json:"From"`
+	To           time.Time `json:"To"`
+	Days         float64   `json:"Days"`
+	Amount       int       `json:"Amount"`
+}
+
+// GenerateInvoice invoices a customer for a period, given as YYYY-MM, that has ended and settled. The SLAs
+// of the customer and their prices during the period are read from the history of the customer contract.
+// Only the owner-admin may invoice customers.
+func (s *SmartContract) GenerateInvoice(ctx contractapi.TransactionContextInterface, customerID string, period string) (*Invoice, error) {
+	err := requireOwnerAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := s.CustomerExist(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("the customer %s does not exist", customerID)
+	}
+
+	periodStart, err := time.Parse(periodLayout, period)
+	if err != nil {
+		return nil, fmt.Errorf("period must be a YYYY-MM month: %v", err)
+	}
+	periodEnd := periodStart.AddDate(0, 1, 0)
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	issuedAt := txTimestamp.AsTime()
+	if issuedAt.Before(periodEnd.Add(settlementPeriod)) {
+		return nil, fmt.Errorf("the period %s can be invoiced from %s", period, periodEnd.Add(settlementPeriod).Format(time.RFC3339))
+	}
+
+	existing, err := readInvoice(ctx, customerID, period)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("the customer %s is already invoiced for %s", customerID, period)
+	}
+
+	lineItems, err := billSLAs(ctx, customerID, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	total := 0
+	for _, lineItem := range lineItems {
+		total += lineItem.Amount
+	}
+
+	invoice := Invoice{
+		CustomerID:  customerID,
+		Period:      period,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		LineItems:   lineItems,
+		Total:       total,
+		IssuedAt:    issuedAt,
+		DueDate:     issuedAt.Add(paymentTerms),
+		Status:      InvoiceStatusOpen,
+	}
+	err = putInvoice(ctx, &invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	err = events.Emit(ctx.GetStub(), events.InvoiceIssued{
+		Version:    events.Version,
+		CustomerID: customerID,
+		Period:     period,
+		Total:      total,
+		DueDate:    invoice.DueDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// MarkInvoicePaid marks the invoice of a customer for a period as paid. Only the owner-admin may do this.
+func (s *SmartContract) MarkInvoicePaid(ctx contractapi.TransactionContextInterface, customerID string, period string) (*Invoice, error) {
+	err := requireOwnerAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	invoice, err := readInvoice(ctx, customerID, period)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, fmt.Errorf("the customer %s has no invoice for %s", customerID, period)
+	}
+	if invoice.Status == InvoiceStatusPaid {
+		return nil, fmt.Errorf("the invoice of %s for %s is already paid", customerID, period)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	paidAt := txTimestamp.AsTime()
+	invoice.Status = InvoiceStatusPaid
+	invoice.PaidAt = &paidAt
+	err = putInvoice(ctx, invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	err = events.Emit(ctx.GetStub(), events.InvoicePaid{
+		Version:    events.Version,
+		CustomerID: customerID,
+		Period:     period,
+		Total:      invoice.Total,
+		PaidAt:     paidAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// GetInvoices returns every invoice of a customer, oldest period first. Only the owner-admin and the
+// customer may read them.
+func (s *SmartContract) GetInvoices(ctx contractapi.TransactionContextInterface, customerID string) ([]*Invoice, error) {
+	err := requireOwnerAdminOrCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return getInvoices(ctx, []string{customerID})
+}
+
+// GetOverdueInvoices returns the invoices of every customer that are still open after their due date.
+// Only the owner-admin may read them.
+func (s *SmartContract) GetOverdueInvoices(ctx contractapi.TransactionContextInterface) ([]*Invoice, error) {
+	err := requireOwnerAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	now := txTimestamp.AsTime()
+
+	invoices, err := getInvoices(ctx, []string{})
+	if err != nil {
+		return nil, err
+	}
+	overdue := []*Invoice{}
+	for _, invoice := range invoices {
+		if invoice.Status == InvoiceStatusOpen && now.After(invoice.DueDate) {
+			overdue = append(overdue, invoice)
+		}
+	}
+	return overdue, nil
+}
+
+// customerVersion is one version of a customer contract, which holds the SLAs, their prices and their
+// statuses from its timestamp until the next version. slas is empty for a version that deleted the customer.
+type customerVersion struct {
+	timestamp time.Time
+	slas      []SLA
+}
+
+// billSLAs returns the line items of the SLAs a customer had between periodStart and periodEnd, read from
+// the history of the customer contract.
+func billSLAs(ctx contractapi.TransactionContextInterface, customerID string, periodStart time.Time, periodEnd time.Time) ([]InvoiceLineItem, error) {
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	versions := []customerVersion{}
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		version := customerVersion{timestamp: modification.Timestamp.AsTime()}
+		if !modification.IsDelete {
+			var customer Customer
+			err = json.Unmarshal(modification.Value, &customer)
+			if err != nil {
+				return nil, err
+			}
+			version.slas = customer.SLAs
+		}
+		versions = append(versions, version)
+	}
+
+	// the history is returned newest first
+	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+		versions[i], versions[j] = versions[j], versions[i]
+	}
+	return lineItemsForVersions(versions, periodStart, periodEnd), nil
+}
+
+// lineItemsForVersions bills the SLAs of the versions of a customer contract, oldest first, between
+// periodStart and periodEnd. The consecutive parts of the period an SLA has the same service level and
+// price are merged into one line item, prorated by the days of the period it covers.
+func lineItemsForVersions(versions []customerVersion, periodStart time.Time, periodEnd time.Time) []InvoiceLineItem {
+	lineItems := []InvoiceLineItem{}
+	// lastLineItem is the index of the latest line item of every SLA
+	lastLineItem := map[string]int{}
+	for i, version := range versions {
+		from := version.timestamp
+		if from.Before(periodStart) {
+			from = periodStart
+		}
+		to := periodEnd
+		if i+1 < len(versions) && versions[i+1].timestamp.Before(to) {
+			to = versions[i+1].timestamp
+		}
+		if !from.Before(to) {
+			continue
+		}
+
+		for _, sla := range version.slas {
+			// suspended and terminated SLAs are not billed, and an SLA that ends is billed until its end
+			if sla.Status == SLAStatusSuspended || sla.Status == SLAStatusTerminated {
+				continue
+			}
+			slaTo := to
+			if end, ok := slaEnd(sla); ok && end.Before(slaTo) {
+				slaTo = end
+			}
+			if !from.Before(slaTo) {
+				continue
+			}
+
+			if last, ok := lastLineItem[sla.ID]; ok {
+				lineItem := &lineItems[last]
+				if lineItem.To.Equal(from) && lineItem.ServiceLevel == sla.ServiceLevel && lineItem.MonthlyCost == sla.AppraisedValue {
+					lineItem.To = slaTo
+					continue
+				}
+			}
+			lastLineItem[sla.ID] = len(lineItems)
+			lineItems = append(lineItems, InvoiceLineItem{
+				SLAID:        sla.ID,
+				ServiceLevel: sla.ServiceLevel,
+				MonthlyCost:  sla.AppraisedValue,
+				From:         from,
+				To:           slaTo,
+			})
+		}
+	}
+
+	periodDays := periodEnd.Sub(periodStart).Hours() / 24
+	for i := range lineItems {
+		days := lineItems[i].To.Sub(lineItems[i].From).Hours() / 24
+		lineItems[i].Days = math.Round(days*100) / 100
+		lineItems[i].Amount = int(math.Round(float64(lineItems[i].MonthlyCost) * days / periodDays))
+	}
+	return lineItems
+}
+
+func putInvoice(ctx contractapi.TransactionContextInterface, invoice *Invoice) error {
+	invoiceKey, err := ctx.GetStub().CreateCompositeKey(invoiceObjectType, []string{invoice.CustomerID, invoice.Period})
+	if err != nil {
+		return err
+	}
+	invoiceJSON, err := json.Marshal(invoice)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(invoiceKey, invoiceJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+	return nil
+}
+
+// readInvoice returns the invoice of a customer for a period, or nil when there is none.
+func readInvoice(ctx contractapi.TransactionContextInterface, customerID string, period string) (*Invoice, error) {
+	invoiceKey, err := ctx.GetStub().CreateCompositeKey(invoiceObjectType, []string{customerID, period})
+	if err != nil {
+		return nil, err
+	}
+	invoiceJSON, err := ctx.GetStub().GetState(invoiceKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if invoiceJSON == nil {
+		return nil, nil
+	}
+
+	var invoice Invoice
+	err = json.Unmarshal(invoiceJSON, &invoice)
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func getInvoices(ctx contractapi.TransactionContextInterface, keys []string) ([]*Invoice, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(invoiceObjectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	invoices := []*Invoice{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var invoice Invoice
+		err = json.Unmarshal(queryResponse.Value, &invoice)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, &invoice)
+	}
+
+	return invoices, nil
+}
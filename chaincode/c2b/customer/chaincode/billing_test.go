@@ -0,0 +1,123 @@
+package customer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLineItemsForVersions(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	sla := func(id string, serviceLevel string, monthlyCost int) SLA {
+		return SLA{ID: id, ServiceLevel: serviceLevel, AppraisedValue: monthlyCost, RenewalPolicy: RenewalPolicyAutoRenew, Status: SLAStatusActive}
+	}
+	withStatus := func(s SLA, status string) SLA {
+		s.Status = status
+		return s
+	}
+	cancelledOn := func(s SLA, cancellationDate time.Time) SLA {
+		s.Status = SLAStatusPendingCancellation
+		s.CancellationDate = &cancellationDate
+		return s
+	}
+	endingOn := func(s SLA, endDate time.Time) SLA {
+		s.RenewalPolicy = RenewalPolicyManual
+		s.EndDate = endDate
+		return s
+	}
+	lineItem := func(id string, serviceLevel string, monthlyCost int, from time.Time, to time.Time, days float64, amount int) InvoiceLineItem {
+		return InvoiceLineItem{SLAID: id, ServiceLevel: serviceLevel, MonthlyCost: monthlyCost, From: from, To: to, Days: days, Amount: amount}
+	}
+
+	// June 2024 has 30 days
+	periodStart, periodEnd := day(time.June, 1), day(time.July, 1)
+	gold := sla("sla1", "gold", 300)
+	platinum := sla("sla1", "platinum", 600)
+	standard := sla("sla2", "standard", 90)
+
+	tests := []struct {
+		name     string
+		versions []customerVersion
+		want     []InvoiceLineItem
+	}{
+		{
+			name:     "whole period",
+			versions: []customerVersion{{day(time.May, 15), []SLA{gold}}},
+			want:     []InvoiceLineItem{lineItem("sla1", "gold", 300, periodStart, periodEnd, 30, 300)},
+		},
+		{
+			name:     "bought during the period",
+			versions: []customerVersion{{day(time.June, 16), []SLA{gold}}},
+			want:     []InvoiceLineItem{lineItem("sla1", "gold", 300, day(time.June, 16), periodEnd, 15, 150)},
+		},
+		{
+			name:     "service level changed during the period",
+			versions: []customerVersion{{day(time.May, 1), []SLA{gold}}, {day(time.June, 11), []SLA{platinum}}},
+			want: []InvoiceLineItem{
+				lineItem("sla1", "gold", 300, periodStart, day(time.June, 11), 10, 100),
+				lineItem("sla1", "platinum", 600, day(time.June, 11), periodEnd, 20, 400),
+			},
+		},
+		{
+			name:     "unchanged SLA is merged across versions",
+			versions: []customerVersion{{day(time.May, 1), []SLA{gold}}, {day(time.June, 11), []SLA{gold, standard}}},
+			want: []InvoiceLineItem{
+				lineItem("sla1", "gold", 300, periodStart, periodEnd, 30, 300),
+				lineItem("sla2", "standard", 90, day(time.June, 11), periodEnd, 20, 60),
+			},
+		},
+		{
+			name: "suspension is not billed",
+			versions: []customerVersion{
+				{day(time.May, 1), []SLA{gold}},
+				{day(time.June, 11), []SLA{withStatus(gold, SLAStatusSuspended)}},
+				{day(time.June, 21), []SLA{gold}},
+			},
+			want: []InvoiceLineItem{
+				lineItem("sla1", "gold", 300, periodStart, day(time.June, 11), 10, 100),
+				lineItem("sla1", "gold", 300, day(time.June, 21), periodEnd, 10, 100),
+			},
+		},
+		{
+			name:     "terminated during the period",
+			versions: []customerVersion{{day(time.May, 1), []SLA{gold}}, {day(time.June, 6), []SLA{withStatus(gold, SLAStatusTerminated)}}},
+			want:     []InvoiceLineItem{lineItem("sla1", "gold", 300, periodStart, day(time.June, 6), 5, 50)},
+		},
+		{
+			name:     "billed until the cancellation date",
+			versions: []customerVersion{{day(time.May, 1), []SLA{cancelledOn(gold, day(time.June, 16))}}},
+			want:     []InvoiceLineItem{lineItem("sla1", "gold", 300, periodStart, day(time.June, 16), 15, 150)},
+		},
+		{
+			name:     "manual SLA billed until its end date",
+			versions: []customerVersion{{day(time.May, 1), []SLA{endingOn(gold, day(time.June, 21))}}},
+			want:     []InvoiceLineItem{lineItem("sla1", "gold", 300, periodStart, day(time.June, 21), 20, 200)},
+		},
+		{
+			name:     "versions after the period are ignored",
+			versions: []customerVersion{{day(time.May, 1), []SLA{gold}}, {day(time.July, 5), []SLA{platinum}}},
+			want:     []InvoiceLineItem{lineItem("sla1", "gold", 300, periodStart, periodEnd, 30, 300)},
+		},
+		{
+			name:     "customer deleted before the period",
+			versions: []customerVersion{{day(time.April, 1), []SLA{gold}}, {day(time.May, 1), nil}},
+			want:     []InvoiceLineItem{},
+		},
+		{
+			name:     "prorated amount is rounded",
+			versions: []customerVersion{{time.Date(2024, time.June, 30, 12, 0, 0, 0, time.UTC), []SLA{standard}}},
+			want:     []InvoiceLineItem{lineItem("sla2", "standard", 90, time.Date(2024, time.June, 30, 12, 0, 0, 0, time.UTC), periodEnd, 0.5, 2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lineItemsForVersions(tt.versions, periodStart, periodEnd)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lineItemsForVersions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
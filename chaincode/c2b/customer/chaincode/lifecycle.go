@@ -0,0 +1,153 @@
+package customer
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/nalle631/fabric-network/chaincode/events"
+)
+
+// The statuses and renewal policies of the SLAs of the mower chaincode.
+const (
+	SLAStatusActive              = "Active"
+	SLAStatusSuspended           = "Suspended"
+	SLAStatusPendingCancellation = "PendingCancellation"
+	SLAStatusTerminated          = "Terminated"
+
+	RenewalPolicyAutoRenew = "AutoRenew"
+	RenewalPolicyManual    = "Manual"
+
+	// slaTermMonths is the length of the term of an SLA, and of every renewal, in the mower chaincode.
+	slaTermMonths = 12
+)
+
+// SuspendSLA pauses an active SLA of a customer, for example over the winter. A suspended SLA is not billed.
+func (s *SmartContract) SuspendSLA(ctx contractapi.TransactionContextInterface, customerID string, slaID string) (*SLA, error) {
+	return changeSLAStatus(ctx, customerID, slaID, [][]byte{[]byte("SuspendSLA"), []byte(slaID)})
+}
+
+// ResumeSLA ends the suspension of an SLA of a customer.
+func (s *SmartContract) ResumeSLA(ctx contractapi.TransactionContextInterface, customerID string, slaID string) (*SLA, error) {
+	return changeSLAStatus(ctx, customerID, slaID, [][]byte{[]byte("ResumeSLA"), []byte(slaID)})
+}
+
+// RenewSLA extends an SLA of a customer by another term, and withdraws its cancellation if it has one.
+func (s *SmartContract) RenewSLA(ctx contractapi.TransactionContextInterface, customerID string, slaID string) (*SLA, error) {
+	return changeSLAStatus(ctx, customerID, slaID, [][]byte{[]byte("RenewSLA"), []byte(slaID)})
+}
+
+// CancelSLA ends an SLA of a customer at the start of cancellationDate, a YYYY-MM-DD date at least the
+// notice period of the mower chaincode away.
+func (s *SmartContract) CancelSLA(ctx contractapi.TransactionContextInterface, customerID string, slaID string, cancellationDate string) (*SLA, error) {
+	return changeSLAStatus(ctx, customerID, slaID, [][]byte{[]byte("CancelSLA"), []byte(slaID), []byte(cancellationDate)})
+}
+
+// SettleSLA stores the changes of status of an SLA of a customer that have become due, in the mower
+// chaincode and in the customer contract.
+func (s *SmartContract) SettleSLA(ctx contractapi.TransactionContextInterface, customerID string, slaID string) (*SLA, error) {
+	return changeSLAStatus(ctx, customerID, slaID, [][]byte{[]byte("SettleSLA"), []byte(slaID)})
+}
+
+// settleSLA applies the changes of status that have become due by now, as settleSLA of the mower chaincode
+// does, so that the customer contract does not show an SLA that has ended as Active.
+func settleSLA(sla *SLA, now time.Time) {
+	if sla.Status == "" {
+		sla.Status = SLAStatusActive
+	}
+
+	switch sla.Status {
+	case SLAStatusPendingCancellation:
+		if sla.CancellationDate != nil && !now.Before(*sla.CancellationDate) {
+			terminatedAt := *sla.CancellationDate
+			sla.Status = SLAStatusTerminated
+			sla.TerminatedAt = &terminatedAt
+		}
+	case SLAStatusActive, SLAStatusSuspended:
+		if sla.EndDate.IsZero() || now.Before(sla.EndDate) {
+			return
+		}
+		if sla.RenewalPolicy == RenewalPolicyAutoRenew {
+			for !now.Before(sla.EndDate) {
+				sla.EndDate = sla.EndDate.AddDate(0, slaTermMonths, 0)
+			}
+			return
+		}
+		terminatedAt := sla.EndDate
+		sla.Status = SLAStatusTerminated
+		sla.TerminatedAt = &terminatedAt
+	}
+}
+
+// slaEnd returns when an SLA that is not renewed ends, as far as the SLA is known by the customer contract.
+func slaEnd(sla SLA) (time.Time, bool) {
+	end := time.Time{}
+	if sla.RenewalPolicy == RenewalPolicyManual && !sla.EndDate.IsZero() {
+		end = sla.EndDate
+	}
+	if sla.Status == SLAStatusPendingCancellation && sla.CancellationDate != nil && (end.IsZero() || sla.CancellationDate.Before(end)) {
+		end = *sla.CancellationDate
+	}
+	return end, !end.IsZero()
+}
+
+// changeSLAStatus changes an SLA with changeSLA and emits SLAStatusChanged.
+func changeSLAStatus(ctx contractapi.TransactionContextInterface, customerID string, slaID string, invokeArgs [][]byte) (*SLA, error) {
+	sla, err := changeSLA(ctx, customerID, slaID, invokeArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = events.Emit(ctx.GetStub(), events.SLAStatusChanged{
+		Version:          events.Version,
+		CustomerID:       customerID,
+		SLAID:            sla.ID,
+		Status:           sla.Status,
+		EndDate:          sla.EndDate,
+		CancellationDate: sla.CancellationDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sla, nil
+}
+
+// changeSLA invokes a transaction of the mower chaincode on an SLA of a customer and stores the SLA it
+// returns in the customer contract.
+func changeSLA(ctx contractapi.TransactionContextInterface, customerID string, slaID string, invokeArgs [][]byte) (*SLA, error) {
+	customer, err := readCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, sla := range customer.SLAs {
+		if sla.ID != slaID {
+			continue
+		}
+
+		response := ctx.GetStub().InvokeChaincode("mower", invokeArgs, ctx.GetStub().GetChannelID())
+		if response.Status != shim.OK {
+			fmt.Printf("failed to invoke chaincode. Got error: %s\n", response.Message)
+			return nil, fmt.Errorf("Failed to invoke chaincode. Got error: %s", response.Message)
+		}
+		var changedSLA SLA
+		err = json.Unmarshal(response.Payload, &changedSLA)
+		if err != nil {
+			return nil, err
+		}
+
+		customer.SLAs[i] = changedSLA
+		customerJSON, err := json.Marshal(customer)
+		if err != nil {
+			return nil, err
+		}
+		err = ctx.GetStub().PutState(customerID, customerJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to put to world state. %v", err)
+		}
+		return &changedSLA, nil
+	}
+	return nil, fmt.Errorf("could not find sla with ID %s", slaID)
+}
@@ -3,9 +3,11 @@ package customer
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/nalle631/fabric-network/chaincode/events"
 )
 
 // SmartContract provides functions for managing an Asset
@@ -13,6 +15,9 @@ type SmartContract struct {
 	contractapi.Contract
 }
 
+// mowerRegistryChaincodeName is the chaincode the mowers of the customers are registered in, on the same channel.
+const mowerRegistryChaincodeName = "mower-registry"
+
 // Asset describes basic details of what makes up a simple asset
 // Insert struct field in alphabetic order => to achieve determinism across languages
 // golang keeps the order when marshal to json but doesn't order automatically
@@ -28,6 +33,21 @@ type SLA struct {
 	MaxGrassLength    float32 `json:"MaxGrassLength"`
 	MinGrassLength    float32 `json:"MinGrassLength"`
 	ID                string  `json:"ID"`
+	MowerSerial       string  `json:"MowerSerial,omitempty"`
+	// QuoteID is the quote in the mower chaincode AppraisedValue was taken from.
+	QuoteID string `json:"QuoteID,omitempty"`
+	// PricingPolicyVersion is the version of the pricing policy in the mower chaincode AppraisedValue was evaluated with.
+	PricingPolicyVersion int `json:"PricingPolicyVersion"`
+	// The lifecycle of the SLA as it was when the mower chaincode last returned it. The changes of status
+	// that have become due since then are applied when the customer is read, and stored by the next
+	// transaction that changes the customer.
+	StartDate        time.Time  `json:"StartDate"`
+	EndDate          time.Time  `json:"EndDate"`
+	RenewalPolicy    string     `json:"RenewalPolicy"`
+	Status           string     `json:"Status"`
+	SuspendedAt      *time.Time `json:"SuspendedAt,omitempty"`
+	CancellationDate *time.Time `json:"CancellationDate,omitempty"`
+	TerminatedAt     *time.Time `json:"TerminatedAt,omitempty"`
 }
 
 // CreateAsset issues a new asset to the world state with given details.
@@ -53,7 +73,10 @@ func (s *SmartContract) CreateCustomer(ctx contractapi.TransactionContextInterfa
 	return ctx.GetStub().PutState(id, customerJSON)
 }
 
-func (s *SmartContract) CreateSLA(ctx contractapi.TransactionContextInterface, customerID, id string, serviceLevel string, targetgrasslength float32, maxgrasslength float32, mingrasslength float32) (*SLA, error) {
+// CreateSLA buys an SLA for the mower with serial number mowerSerial, which must be registered to the customer
+// in the mower registry. quoteID is a quote of the mower chaincode to buy the SLA at, or empty to buy it at
+// the current price. renewalPolicy is AutoRenew, Manual or empty for AutoRenew.
+func (s *SmartContract) CreateSLA(ctx contractapi.TransactionContextInterface, customerID, id string, mowerSerial string, quoteID string, renewalPolicy string, serviceLevel string, targetgrasslength float32, maxgrasslength float32, mingrasslength float32) (*SLA, error) {
 	fmt.Println("In CreateSLA in customer contract")
 	exists, err := s.CustomerExist(ctx, customerID)
 	if err != nil {
@@ -70,6 +93,12 @@ func (s *SmartContract) CreateSLA(ctx contractapi.TransactionContextInterface, c
 		return nil, readSLAerror
 	}
 
+	registryArgs := [][]byte{[]byte("ValidateMower"), []byte(mowerSerial), []byte(customerID)}
+	registryResponse := ctx.GetStub().InvokeChaincode(mowerRegistryChaincodeName, registryArgs, ctx.GetStub().GetChannelID())
+	if registryResponse.Status != shim.OK {
+		return nil, fmt.Errorf("mower %s can not get an SLA: %s", mowerSerial, registryResponse.Message)
+	}
+
 	fmt.Println("servicelevel: ", serviceLevel)
 	targetgrasslength_string := fmt.Sprintf("%f", targetgrasslength)
 	fmt.Println("targetgrasslength: ", targetgrasslength_string)
@@ -78,13 +107,13 @@ func (s *SmartContract) CreateSLA(ctx contractapi.TransactionContextInterface, c
 	mingrasslength_string := fmt.Sprintf("%f", mingrasslength)
 	fmt.Println("Mingrasslength: ", mingrasslength_string)
 
-	invokeArgs := [][]byte{[]byte("CreateSLA"), []byte(id), []byte(serviceLevel), []byte(targetgrasslength_string), []byte(maxgrasslength_string), []byte(mingrasslength_string)}
+	invokeArgs := [][]byte{[]byte("CreateSLA"), []byte(id), []byte(mowerSerial), []byte(quoteID), []byte(renewalPolicy), []byte(serviceLevel), []byte(targetgrasslength_string), []byte(maxgrasslength_string), []byte(mingrasslength_string)}
 	fmt.Println("Invoke args: ", invokeArgs)
 	response := ctx.GetStub().InvokeChaincode("mower", invokeArgs, ctx.GetStub().GetChannelID())
 	fmt.Println("response status: ", response.Status)
 	if response.Status != shim.OK {
-		fmt.Println("failed to invoke chaincode. Got error: %s", response.Payload)
-		return nil, fmt.Errorf("Failed to invoke chaincode. Got error: %s", response.Payload)
+		fmt.Printf("failed to invoke chaincode. Got error: %s\n", response.Message)
+		return nil, fmt.Errorf("Failed to invoke chaincode. Got error: %s", response.Message)
 	}
 	var createdSLA SLA
 	err = json.Unmarshal(response.Payload, &createdSLA)
@@ -98,11 +127,35 @@ func (s *SmartContract) CreateSLA(ctx contractapi.TransactionContextInterface, c
 	if err != nil {
 		return nil, err
 	}
-	return &createdSLA, ctx.GetStub().PutState(customerID, customerJSON)
+	err = ctx.GetStub().PutState(customerID, customerJSON)
+	if err != nil {
+		return nil, err
+	}
 
+	err = events.Emit(ctx.GetStub(), events.SLACreated{
+		Version:        events.Version,
+		CustomerID:     customerID,
+		SLAID:          createdSLA.ID,
+		ServiceLevel:   createdSLA.ServiceLevel,
+		AppraisedValue: createdSLA.AppraisedValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &createdSLA, nil
 }
 
+// ReadCustomer returns a customer, with the changes of status of its SLAs that have become due applied.
 func (s *SmartContract) ReadCustomer(ctx contractapi.TransactionContextInterface, id string) (*Customer, error) {
+	return readCustomer(ctx, id)
+}
+
+func readCustomer(ctx contractapi.TransactionContextInterface, id string) (*Customer, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+
 	customerJSON, err := ctx.GetStub().GetState(id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from world state: %v", err)
@@ -117,6 +170,9 @@ func (s *SmartContract) ReadCustomer(ctx contractapi.TransactionContextInterface
 		return nil, err
 	}
 
+	for i := range customer.SLAs {
+		settleSLA(&customer.SLAs[i], txTimestamp.AsTime())
+	}
 	return &customer, nil
 }
 
@@ -144,8 +200,8 @@ func (s *SmartContract) UpdateTargetGrassLength(ctx contractapi.TransactionConte
 			response := ctx.GetStub().InvokeChaincode("mower", invokeArgs, ctx.GetStub().GetChannelID())
 			fmt.Println("response status: ", response.Status)
 			if response.Status != shim.OK {
-				fmt.Println("failed to invoke chaincode. Got error: %s", response.Payload)
-				return fmt.Errorf("Failed to invoke chaincode. Got error: %s", response.Payload)
+				fmt.Printf("failed to invoke chaincode. Got error: %s\n", response.Message)
+				return fmt.Errorf("Failed to invoke chaincode. Got error: %s", response.Message)
 			}
 			var newSLA SLA
 			err = json.Unmarshal(response.Payload, &newSLA)
@@ -174,7 +230,7 @@ func (s *SmartContract) UpdateServiceLevel(ctx contractapi.TransactionContextInt
 		return err
 	}
 	if !exists {
-		fmt.Println("the customer %s does not exist", customerID)
+		fmt.Printf("the customer %s does not exist\n", customerID)
 		return fmt.Errorf("the customer %s does not exist", customerID)
 	}
 
@@ -189,8 +245,8 @@ func (s *SmartContract) UpdateServiceLevel(ctx contractapi.TransactionContextInt
 			response := ctx.GetStub().InvokeChaincode("mower", invokeArgs, ctx.GetStub().GetChannelID())
 			fmt.Println("response status: ", response.Status)
 			if response.Status != shim.OK {
-				fmt.Println("failed to invoke chaincode. Got error: %s", response.Payload)
-				return fmt.Errorf("Failed to invoke chaincode. Got error: %s", response.Payload)
+				fmt.Printf("failed to invoke chaincode. Got error: %s\n", response.Message)
+				return fmt.Errorf("Failed to invoke chaincode. Got error: %s", response.Message)
 			}
 
 			var newSLA SLA
@@ -207,7 +263,13 @@ func (s *SmartContract) UpdateServiceLevel(ctx contractapi.TransactionContextInt
 			if err != nil {
 				return err
 			}
-			return nil
+			return events.Emit(ctx.GetStub(), events.ServiceLevelChanged{
+				Version:        events.Version,
+				CustomerID:     customerID,
+				SLAID:          newSLA.ID,
+				ServiceLevel:   newSLA.ServiceLevel,
+				AppraisedValue: newSLA.AppraisedValue,
+			})
 		}
 	}
 	return fmt.Errorf("could not update grasslength interval")
@@ -236,8 +298,8 @@ func (s *SmartContract) UpdateGrassLengthInterval(ctx contractapi.TransactionCon
 			response := ctx.GetStub().InvokeChaincode("mower", invokeArgs, ctx.GetStub().GetChannelID())
 			fmt.Println("response status: ", response.Status)
 			if response.Status != shim.OK {
-				fmt.Println("failed to invoke chaincode. Got error: %s", response.Payload)
-				return fmt.Errorf("Failed to invoke chaincode. Got error: %s", response.Payload)
+				fmt.Printf("failed to invoke chaincode. Got error: %s\n", response.Message)
+				return fmt.Errorf("Failed to invoke chaincode. Got error: %s", response.Message)
 			}
 
 			var newSLA SLA
@@ -260,52 +322,16 @@ func (s *SmartContract) UpdateGrassLengthInterval(ctx contractapi.TransactionCon
 	return fmt.Errorf("could not update grasslength interval")
 }
 
-// DeleteAsset deletes an given asset from the world state.
+// RemoveSLA terminates an SLA of a customer right away. The SLA is kept with the status Terminated, so
+// that it stays in the history and on the invoices of the customer.
 func (s *SmartContract) RemoveSLA(ctx contractapi.TransactionContextInterface, customerID string, slaID string) error {
-	exists, err := s.CustomerExist(ctx, customerID)
+	_, err := changeSLA(ctx, customerID, slaID, [][]byte{[]byte("TerminateSLA"), []byte(slaID)})
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return fmt.Errorf("the asset %s does not exist", customerID)
-	}
-
-	customer, readSLAerror := s.ReadCustomer(ctx, customerID)
-	if err != nil {
-		return readSLAerror
-	}
-
-	invokeArgs := [][]byte{[]byte("DeleteSLA"), []byte(slaID)}
-	for i, SLA := range customer.SLAs {
-		if SLA.ID == slaID {
-			response := ctx.GetStub().InvokeChaincode("mower", invokeArgs, ctx.GetStub().GetChannelID())
-			fmt.Println("response status: ", response.Status)
-			if response.Status != shim.OK {
-				fmt.Println("failed to invoke chaincode. Got error: %s", response.Payload)
-				return fmt.Errorf("Failed to invoke chaincode. Got error: %s", response.Payload)
-			}
-			fmt.Println("CustomerSLAs before remove: ", customer.SLAs)
-			newSLAs := remove(customer.SLAs, i)
-			customer.SLAs = newSLAs
-			customerJSON, err := json.Marshal(customer)
-			if err != nil {
-				return err
-			}
-			fmt.Println("CustomerSLAs after remove: ", customer.SLAs)
-			return ctx.GetStub().PutState(customerID, customerJSON)
-		}
-	}
-	return fmt.Errorf("could not find sla with ID %s", slaID)
-}
-
-func remove(slice []SLA, i int) []SLA {
-	if i >= 0 && i < len(slice) {
-		return append(slice[:i], slice[i+1:]...) // ... is the spread operator
-	}
-	return slice // If index is out of bounds, return the original slice
+	return events.Emit(ctx.GetStub(), events.SLARemoved{Version: events.Version, CustomerID: customerID, SLAID: slaID})
 }
 
-// AssetExists returns true when asset with given ID exists in world state
 func (s *SmartContract) CustomerExist(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
 	customerJSON, err := ctx.GetStub().GetState(id)
 	if err != nil {
@@ -5,6 +5,7 @@ go 1.22.1
 require (
 	github.com/hyperledger/fabric-chaincode-go v0.0.0-20240124143825-7dec3c7e7d45
 	github.com/hyperledger/fabric-contract-api-go v1.2.2
+	github.com/nalle631/fabric-network/chaincode/events v0.0.0
 )
 
 require (
@@ -33,3 +34,5 @@ require (
 	google.golang.org/protobuf v1.31.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/nalle631/fabric-network/chaincode/events => ../../events
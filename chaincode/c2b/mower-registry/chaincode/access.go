@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// ownerMSPID is the organisation that sells the mowers and the SLAs.
+	ownerMSPID = "Org2MSP"
+
+	// roleAttribute and customerAttribute are X.509 certificate attributes, set when the identity is
+	// registered with the CA. customerAttribute is the customer ID of an identity issued to a customer.
+	roleAttribute     = "role"
+	roleOwnerAdmin    = "owner-admin"
+	customerAttribute = "customer"
+)
+
+// requireOwnerAdmin fails unless the caller is an owner-admin of the owner organisation.
+func requireOwnerAdmin(ctx contractapi.TransactionContextInterface) error {
+	isOwnerAdmin, err := callerIsOwnerAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if !isOwnerAdmin {
+		return fmt.Errorf("only the %s of %s may do this", roleOwnerAdmin, ownerMSPID)
+	}
+	return nil
+}
+
+// requireOwnerAdminOrCustomer fails unless the caller is an owner-admin of the owner organisation or
+// carries the customer attribute of customerID.
+func requireOwnerAdminOrCustomer(ctx contractapi.TransactionContextInterface, customerID string) error {
+	isOwnerAdmin, err := callerIsOwnerAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if isOwnerAdmin {
+		return nil
+	}
+	customer, _, err := ctx.GetClientIdentity().GetAttributeValue(customerAttribute)
+	if err != nil {
+		return fmt.Errorf("failed to get client customer: %v", err)
+	}
+	if customer == "" || customer != customerID {
+		return fmt.Errorf("only the %s of %s or the customer %s may do this", roleOwnerAdmin, ownerMSPID, customerID)
+	}
+	return nil
+}
+
+func callerIsOwnerAdmin(ctx contractapi.TransactionContextInterface) (bool, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return false, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	role, _, err := ctx.GetClientIdentity().GetAttributeValue(roleAttribute)
+	if err != nil {
+		return false, fmt.Errorf("failed to get client role: %v", err)
+	}
+	return mspID == ownerMSPID && role == roleOwnerAdmin, nil
+}
@@ -0,0 +1,340 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/nalle631/fabric-network/chaincode/events"
+)
+
+// SmartContract provides functions for managing the physical mowers of the customers
+type SmartContract struct {
+	contractapi.Contract
+}
+
+const (
+	MowerStatusActive         = "Active"
+	MowerStatusDecommissioned = "Decommissioned"
+
+	// mowerObjectType is the composite key prefix of mowers, keyed by serial number.
+	mowerObjectType = "mower"
+	// customerIndex lists the mowers of a customer.
+	customerIndex = "customer~serial"
+
+	// customerChaincodeName is the chaincode the customers are kept in, on the same channel.
+	customerChaincodeName = "customer"
+
+	warrantyDateLayout = "2006-01-02"
+)
+
+// Mower is a physical mower installed at a customer. A decommissioned mower is kept so that its
+// SLAs and jobs can still be traced to it.
+type Mower struct {
+	SerialNumber        string     `json:"SerialNumber"`
+	Model               string     `json:"Model"`
+	CustomerID          string     `json:"CustomerID"`
+	InstallationAddress string     `json:"InstallationAddress"`
+	FirmwareVersion     string     `json:"FirmwareVersion"`
+	WarrantyStart       time.Time  `json:"WarrantyStart"`
+	WarrantyEnd         time.Time  `json:"WarrantyEnd"`
+	Status              string     `json:"Status"`
+	RegisteredAt        time.Time  `json:"RegisteredAt"`
+	DecommissionedAt    *time.Time `json:"DecommissionedAt,omitempty"`
+}
+
+// RegisterMower registers a mower to a customer. The warranty dates are given as YYYY-MM-DD. Only the
+// owner-admin may register mowers.
+func (s *SmartContract) RegisterMower(ctx contractapi.TransactionContextInterface, serialNumber string, model string, customerID string, installationAddress string, firmwareVersion string, warrantyStart string, warrantyEnd string) (*Mower, error) {
+	err := requireOwnerAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if serialNumber == "" {
+		return nil, fmt.Errorf("serial number can not be empty")
+	}
+	exists, err := mowerExists(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("the mower %s is already registered", serialNumber)
+	}
+	err = requireCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	warrantyStartDate, err := time.Parse(warrantyDateLayout, warrantyStart)
+	if err != nil {
+		return nil, fmt.Errorf("warranty start must be a YYYY-MM-DD date: %v", err)
+	}
+	warrantyEndDate, err := time.Parse(warrantyDateLayout, warrantyEnd)
+	if err != nil {
+		return nil, fmt.Errorf("warranty end must be a YYYY-MM-DD date: %v", err)
+	}
+	if warrantyEndDate.Before(warrantyStartDate) {
+		return nil, fmt.Errorf("warranty end %s is before warranty start %s", warrantyEnd, warrantyStart)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	mower := Mower{
+		SerialNumber:        serialNumber,
+		Model:               model,
+		CustomerID:          customerID,
+		InstallationAddress: installationAddress,
+		FirmwareVersion:     firmwareVersion,
+		WarrantyStart:       warrantyStartDate,
+		WarrantyEnd:         warrantyEndDate,
+		Status:              MowerStatusActive,
+		RegisteredAt:        txTimestamp.AsTime(),
+	}
+	err = putMower(ctx, &mower, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = events.Emit(ctx.GetStub(), events.MowerRegistered{
+		Version:      events.Version,
+		SerialNumber: serialNumber,
+		Model:        model,
+		CustomerID:   customerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &mower, nil
+}
+
+// TransferMower moves an active mower to another customer, for example when a house is sold. An empty
+// installationAddress keeps the address. The SLAs of the previous customer are not moved. Only the
+// owner-admin or the customer that owns the mower may transfer it.
+func (s *SmartContract) TransferMower(ctx contractapi.TransactionContextInterface, serialNumber string, customerID string, installationAddress string) (*Mower, error) {
+	mower, err := readMower(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	err = requireOwnerAdminOrCustomer(ctx, mower.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if mower.Status != MowerStatusActive {
+		return nil, fmt.Errorf("the mower %s is %s and can not be transferred", serialNumber, mower.Status)
+	}
+	if mower.CustomerID == customerID {
+		return nil, fmt.Errorf("the mower %s already belongs to %s", serialNumber, customerID)
+	}
+	err = requireCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *mower
+	mower.CustomerID = customerID
+	if installationAddress != "" {
+		mower.InstallationAddress = installationAddress
+	}
+	err = putMower(ctx, mower, &previous)
+	if err != nil {
+		return nil, err
+	}
+
+	err = events.Emit(ctx.GetStub(), events.MowerTransferred{
+		Version:        events.Version,
+		SerialNumber:   serialNumber,
+		FromCustomerID: previous.CustomerID,
+		ToCustomerID:   customerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mower, nil
+}
+
+// DecommissionMower takes a mower out of service. No new SLAs or jobs can be created for it. Only the
+// owner-admin may decommission mowers.
+func (s *SmartContract) DecommissionMower(ctx contractapi.TransactionContextInterface, serialNumber string) (*Mower, error) {
+	err := requireOwnerAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mower, err := readMower(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if mower.Status != MowerStatusActive {
+		return nil, fmt.Errorf("the mower %s is already %s", serialNumber, mower.Status)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	decommissionedAt := txTimestamp.AsTime()
+	previous := *mower
+	mower.Status = MowerStatusDecommissioned
+	mower.DecommissionedAt = &decommissionedAt
+	err = putMower(ctx, mower, &previous)
+	if err != nil {
+		return nil, err
+	}
+
+	err = events.Emit(ctx.GetStub(), events.MowerDecommissioned{
+		Version:      events.Version,
+		SerialNumber: serialNumber,
+		CustomerID:   mower.CustomerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mower, nil
+}
+
+// ReadMower returns a registered mower.
+func (s *SmartContract) ReadMower(ctx contractapi.TransactionContextInterface, serialNumber string) (*Mower, error) {
+	return readMower(ctx, serialNumber)
+}
+
+// ValidateMower returns a mower if it is registered and active, and when customerID is not empty, owned
+// by that customer. The customer chaincode and the job contract call it before tying an SLA or a job to a mower.
+func (s *SmartContract) ValidateMower(ctx contractapi.TransactionContextInterface, serialNumber string, customerID string) (*Mower, error) {
+	mower, err := readMower(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if mower.Status != MowerStatusActive {
+		return nil, fmt.Errorf("the mower %s is %s", serialNumber, mower.Status)
+	}
+	if customerID != "" && mower.CustomerID != customerID {
+		return nil, fmt.Errorf("the mower %s does not belong to %s", serialNumber, customerID)
+	}
+	return mower, nil
+}
+
+// GetMowersByCustomer returns every mower of a customer, decommissioned mowers included.
+func (s *SmartContract) GetMowersByCustomer(ctx contractapi.TransactionContextInterface, customerID string) ([]*Mower, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(customerIndex, []string{customerID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	mowers := []*Mower{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		mower, err := readMower(ctx, keyParts[1])
+		if err != nil {
+			return nil, err
+		}
+		mowers = append(mowers, mower)
+	}
+
+	return mowers, nil
+}
+
+// putMower stores a mower and its customer index entry. previous is the mower as it was stored before,
+// and nil for a new mower.
+func putMower(ctx contractapi.TransactionContextInterface, mower *Mower, previous *Mower) error {
+	mowerKey, err := ctx.GetStub().CreateCompositeKey(mowerObjectType, []string{mower.SerialNumber})
+	if err != nil {
+		return err
+	}
+	mowerJSON, err := json.Marshal(mower)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(mowerKey, mowerJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	if previous != nil && previous.CustomerID != mower.CustomerID {
+		previousIndexKey, err := ctx.GetStub().CreateCompositeKey(customerIndex, []string{previous.CustomerID, previous.SerialNumber})
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().DelState(previousIndexKey)
+		if err != nil {
+			return fmt.Errorf("failed to delete from world state. %v", err)
+		}
+	}
+	indexKey, err := ctx.GetStub().CreateCompositeKey(customerIndex, []string{mower.CustomerID, mower.SerialNumber})
+	if err != nil {
+		return err
+	}
+	// the value of an index entry is not used, the mower is read by its serial number
+	err = ctx.GetStub().PutState(indexKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+	return nil
+}
+
+func readMower(ctx contractapi.TransactionContextInterface, serialNumber string) (*Mower, error) {
+	mowerJSON, err := getMowerState(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if mowerJSON == nil {
+		return nil, fmt.Errorf("the mower %s is not registered", serialNumber)
+	}
+
+	var mower Mower
+	err = json.Unmarshal(mowerJSON, &mower)
+	if err != nil {
+		return nil, err
+	}
+	return &mower, nil
+}
+
+func mowerExists(ctx contractapi.TransactionContextInterface, serialNumber string) (bool, error) {
+	mowerJSON, err := getMowerState(ctx, serialNumber)
+	if err != nil {
+		return false, err
+	}
+	return mowerJSON != nil, nil
+}
+
+func getMowerState(ctx contractapi.TransactionContextInterface, serialNumber string) ([]byte, error) {
+	mowerKey, err := ctx.GetStub().CreateCompositeKey(mowerObjectType, []string{serialNumber})
+	if err != nil {
+		return nil, err
+	}
+	mowerJSON, err := ctx.GetStub().GetState(mowerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	return mowerJSON, nil
+}
+
+// requireCustomer fails unless the customer has a customer contract in the customer chaincode.
+func requireCustomer(ctx contractapi.TransactionContextInterface, customerID string) error {
+	invokeArgs := [][]byte{[]byte("CustomerExist"), []byte(customerID)}
+	response := ctx.GetStub().InvokeChaincode(customerChaincodeName, invokeArgs, ctx.GetStub().GetChannelID())
+	if response.Status != shim.OK {
+		return fmt.Errorf("failed to read customer %s from %s: %s", customerID, customerChaincodeName, response.Message)
+	}
+
+	var exists bool
+	err := json.Unmarshal(response.Payload, &exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the customer %s does not exist", customerID)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	registry "github.com/nalle631/fabric-network/chaincode/c2b/mower-registry/chaincode"
+)
+
+func main() {
+	registryChaincode, err := contractapi.NewChaincode(&registry.SmartContract{})
+	if err != nil {
+		log.Panicf("Error creating mower-registry chaincode: %v", err)
+	}
+
+	if err := registryChaincode.Start(); err != nil {
+		log.Panicf("Error starting mower-registry chaincode: %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+package mower
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/nalle631/fabric-network/chaincode/events"
+)
+
+// customerChaincodeName is the name the customer chaincode is deployed under. The lifecycle of an SLA is
+// only changed when the customer chaincode invokes the mower chaincode, so that the two always agree on it.
+const customerChaincodeName = "customer"
+
+const (
+	SLAStatusActive              = "Active"
+	SLAStatusSuspended           = "Suspended"
+	SLAStatusPendingCancellation = "PendingCancellation"
+	SLAStatusTerminated          = "Terminated"
+
+	// RenewalPolicyAutoRenew renews an SLA by another term every time it reaches its EndDate.
+	RenewalPolicyAutoRenew = "AutoRenew"
+	// RenewalPolicyManual ends an SLA at its EndDate unless it is renewed with RenewSLA before that.
+	RenewalPolicyManual = "Manual"
+
+	// slaTermMonths is the length of the term of an SLA, and of every renewal.
+	slaTermMonths = 12
+	// noticePeriod is how long after it is cancelled an SLA can end at the earliest.
+	noticePeriod = 30 * 24 * time.Hour
+
+	cancellationDateLayout = "2006-01-02"
+)
+
+// SuspendSLA pauses an active SLA, for example over the winter. A suspended SLA is not billed.
+func (s *SmartContract) SuspendSLA(ctx contractapi.TransactionContextInterface, id string) (*SLA, error) {
+	sla, now, err := readSLAForLifecycle(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sla.Status != SLAStatusActive {
+		return nil, fmt.Errorf("the SLA %s is %s, only an Active SLA can be suspended", id, sla.Status)
+	}
+
+	sla.Status = SLAStatusSuspended
+	sla.SuspendedAt = &now
+	return putSLAStatus(ctx, sla)
+}
+
+// ResumeSLA ends the suspension of an SLA.
+func (s *SmartContract) ResumeSLA(ctx contractapi.TransactionContextInterface, id string) (*SLA, error) {
+	sla, _, err := readSLAForLifecycle(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sla.Status != SLAStatusSuspended {
+		return nil, fmt.Errorf("the SLA %s is %s, only a Suspended SLA can be resumed", id, sla.Status)
+	}
+
+	sla.Status = SLAStatusActive
+	sla.SuspendedAt = nil
+	return putSLAStatus(ctx, sla)
+}
+
+// RenewSLA extends an SLA by another term from its EndDate. Renewing an SLA that is pending cancellation
+// withdraws the cancellation.
+func (s *SmartContract) RenewSLA(ctx contractapi.TransactionContextInterface, id string) (*SLA, error) {
+	sla, _, err := readSLAForLifecycle(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sla.Status == SLAStatusTerminated {
+		return nil, fmt.Errorf("the SLA %s is %s and can not be renewed", id, sla.Status)
+	}
+
+	if sla.Status == SLAStatusPendingCancellation {
+		sla.Status = SLAStatusActive
+		sla.CancellationDate = nil
+	}
+	sla.EndDate = sla.EndDate.AddDate(0, slaTermMonths, 0)
+	return putSLAStatus(ctx, sla)
+}
+
+// CancelSLA ends an active SLA at the start of cancellationDate, given as YYYY-MM-DD in UTC, which must
+// be at least the notice period away. The SLA is billed until then.
+func (s *SmartContract) CancelSLA(ctx contractapi.TransactionContextInterface, id string, cancellationDate string) (*SLA, error) {
+	sla, now, err := readSLAForLifecycle(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sla.Status != SLAStatusActive {
+		return nil, fmt.Errorf("the SLA %s is %s, only an Active SLA can be cancelled", id, sla.Status)
+	}
+
+	cancellationTime, err := time.Parse(cancellationDateLayout, cancellationDate)
+	if err != nil {
+		return nil, fmt.Errorf("cancellation date must be a YYYY-MM-DD date: %v", err)
+	}
+	earliest := now.Add(noticePeriod)
+	if cancellationTime.Before(earliest) {
+		return nil, fmt.Errorf("the notice period is %d days, the SLA %s can end on %s at the earliest", int(noticePeriod.Hours()/24), id, earliest.Format(cancellationDateLayout))
+	}
+
+	sla.Status = SLAStatusPendingCancellation
+	sla.CancellationDate = &cancellationTime
+	return putSLAStatus(ctx, sla)
+}
+
+// TerminateSLA ends an SLA right away. The SLA is kept with the status Terminated so that its history
+// and invoices can still be traced to it.
+func (s *SmartContract) TerminateSLA(ctx contractapi.TransactionContextInterface, id string) (*SLA, error) {
+	sla, now, err := readSLAForLifecycle(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sla.Status == SLAStatusTerminated {
+		return nil, fmt.Errorf("the SLA %s is already %s", id, sla.Status)
+	}
+
+	sla.Status = SLAStatusTerminated
+	sla.TerminatedAt = &now
+	err = putSLA(ctx, sla)
+	if err != nil {
+		return nil, err
+	}
+
+	err = events.Emit(ctx.GetStub(), events.SLARemoved{Version: events.Version, SLAID: id})
+	if err != nil {
+		return nil, err
+	}
+	return sla, nil
+}
+
+// SettleSLA stores the changes of status of an SLA that have become due, a pending cancellation that has
+// taken effect or an EndDate that has been reached, so that they are also seen by the customer chaincode
+// and in the history of the SLA. It can be submitted through the customer chaincode for any SLA, for example
+// every night, and only stores and emits SLAStatusChanged when something changed.
+func (s *SmartContract) SettleSLA(ctx contractapi.TransactionContextInterface, id string) (*SLA, error) {
+	err := requireCustomerChaincode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	slaJSON, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if slaJSON == nil {
+		return nil, fmt.Errorf("the asset %s does not exist", id)
+	}
+	var stored SLA
+	err = json.Unmarshal(slaJSON, &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	sla, err := readSLA(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sla.Status == stored.Status && sla.EndDate.Equal(stored.EndDate) {
+		return sla, nil
+	}
+	return putSLAStatus(ctx, sla)
+}
+
+// settleSLA applies the changes of status that have become due by now: a pending cancellation that has
+// taken effect, and an SLA that has reached its EndDate, which is renewed or ends by its renewal policy.
+// SLAs created before they had a lifecycle are Active and have no EndDate.
+func settleSLA(sla *SLA, now time.Time) {
+	if sla.Status == "" {
+		sla.Status = SLAStatusActive
+	}
+
+	switch sla.Status {
+	case SLAStatusPendingCancellation:
+		if sla.CancellationDate != nil && !now.Before(*sla.CancellationDate) {
+			terminatedAt := *sla.CancellationDate
+			sla.Status = SLAStatusTerminated
+			sla.TerminatedAt = &terminatedAt
+		}
+	case SLAStatusActive, SLAStatusSuspended:
+		if sla.EndDate.IsZero() || now.Before(sla.EndDate) {
+			return
+		}
+		if sla.RenewalPolicy == RenewalPolicyAutoRenew {
+			for !now.Before(sla.EndDate) {
+				sla.EndDate = sla.EndDate.AddDate(0, slaTermMonths, 0)
+			}
+			return
+		}
+		terminatedAt := sla.EndDate
+		sla.Status = SLAStatusTerminated
+		sla.TerminatedAt = &terminatedAt
+	}
+}
+
+// requireNotTerminated fails for an SLA that can no longer be changed.
+func requireNotTerminated(sla *SLA) error {
+	if sla.Status == SLAStatusTerminated {
+		return fmt.Errorf("the SLA %s is %s and can not be changed", sla.ID, sla.Status)
+	}
+	return nil
+}
+
+func readSLAForLifecycle(ctx contractapi.TransactionContextInterface, id string) (*SLA, time.Time, error) {
+	err := requireCustomerChaincode(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	sla, err := readSLA(ctx, id)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return sla, txTimestamp.AsTime(), nil
+}
+
+// requireCustomerChaincode fails unless the transaction was sent to the customer chaincode, which then
+// invoked this chaincode. A transaction sent straight to the mower chaincode can not change the lifecycle of an SLA.
+func requireCustomerChaincode(ctx contractapi.TransactionContextInterface) error {
+	signedProposal, err := ctx.GetStub().GetSignedProposal()
+	if err != nil {
+		return err
+	}
+
+	var proposal peer.Proposal
+	err = proto.Unmarshal(signedProposal.ProposalBytes, &proposal)
+	if err != nil {
+		return fmt.Errorf("failed to read the proposal: %v", err)
+	}
+	var payload peer.ChaincodeProposalPayload
+	err = proto.Unmarshal(proposal.Payload, &payload)
+	if err != nil {
+		return fmt.Errorf("failed to read the proposal payload: %v", err)
+	}
+	var invocationSpec peer.ChaincodeInvocationSpec
+	err = proto.Unmarshal(payload.Input, &invocationSpec)
+	if err != nil {
+		return fmt.Errorf("failed to read the invoked chaincode: %v", err)
+	}
+
+	invoked := invocationSpec.GetChaincodeSpec().GetChaincodeId().GetName()
+	if invoked != customerChaincodeName {
+		return fmt.Errorf("the lifecycle of an SLA can only be changed through the %s chaincode, the transaction was sent to %s", customerChaincodeName, invoked)
+	}
+	return nil
+}
+
+// putSLAStatus stores an SLA whose status or dates changed and emits SLAStatusChanged.
+func putSLAStatus(ctx contractapi.TransactionContextInterface, sla *SLA) (*SLA, error) {
+	err := putSLA(ctx, sla)
+	if err != nil {
+		return nil, err
+	}
+
+	// when the customer chaincode changes the status, its own event is the one delivered
+	err = events.Emit(ctx.GetStub(), events.SLAStatusChanged{
+		Version:          events.Version,
+		SLAID:            sla.ID,
+		Status:           sla.Status,
+		EndDate:          sla.EndDate,
+		CancellationDate: sla.CancellationDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sla, nil
+}
+
+func putSLA(ctx contractapi.TransactionContextInterface, sla *SLA) error {
+	slaJSON, err := json.Marshal(sla)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(sla.ID, slaJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+	return nil
+}
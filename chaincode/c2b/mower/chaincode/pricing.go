@@ -0,0 +1,264 @@
+package mower
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// pricingPolicyObjectType is the composite key prefix of the pricing policy versions, keyed by the
+	// zero padded version so that the versions are iterated in order.
+	pricingPolicyObjectType = "PricingPolicy"
+
+	// ownerMSPID is the organisation that sells the SLAs, only its owner-admin may change the pricing and
+	// the validation rules.
+	ownerMSPID     = "Org2MSP"
+	roleAttribute  = "role"
+	roleOwnerAdmin = "owner-admin"
+
+	// FormulaInverseWeighted prices an SLA higher the narrower its grass length interval and the shorter
+	// its target length: base cost * (1 + SpreadWeight/spread + TargetWeight/target).
+	FormulaInverseWeighted = "inverse-weighted"
+	// FormulaFlat prices every SLA of a service level at its base cost.
+	FormulaFlat = "flat"
+)
+
+// PricingPolicy is one version of how SLAs are priced. An SLA is priced by the version with the latest
+// EffectiveFrom that is not after the time it is evaluated.
+type PricingPolicy struct {
+	Version       int       `json:"Version"`
+	Formula       string    `json:"Formula"`
+	BaseCosts     BaseCosts `json:"BaseCosts"`
+	SpreadWeight  float32   `json:"SpreadWeight"`
+	TargetWeight  float32   `json:"TargetWeight"`
+	EffectiveFrom time.Time `json:"EffectiveFrom"`
+	SetAt         time.Time `json:"SetAt"`
+	SetBy         string    `json:"SetBy"`
+}
+
+// BaseCosts is the monthly base cost of every service level.
+type BaseCosts struct {
+	Standard float32 `json:"Standard"`
+	Gold     float32 `json:"Gold"`
+	Platinum float32 `json:"Platinum"`
+}
+
+// SLAEvaluation is the monthly cost of an SLA and the pricing policy it was evaluated with.
+type SLAEvaluation struct {
+	MonthlyCost   int            `json:"MonthlyCost"`
+	PricingPolicy *PricingPolicy `json:"PricingPolicy"`
+}
+
+// defaultPricingPolicy is how SLAs were priced before pricing policies were kept on the ledger. It is
+// used as version 0 until the first policy is added.
+var defaultPricingPolicy = PricingPolicy{
+	Version:      0,
+	Formula:      FormulaInverseWeighted,
+	BaseCosts:    BaseCosts{Standard: 50, Gold: 100, Platinum: 200},
+	SpreadWeight: 0.7,
+	TargetWeight: 0.3,
+}
+
+// UpdatePricingPolicy adds a new version of the pricing policy. effectiveFrom is an RFC 3339 time that must
+// not be in the past. SLAs keep the price they were created with until they are changed.
+func (s *SmartContract) UpdatePricingPolicy(ctx contractapi.TransactionContextInterface, formula string, standardBaseCost float32, goldBaseCost float32, platinumBaseCost float32, spreadWeight float32, targetWeight float32, effectiveFrom string) (*PricingPolicy, error) {
+	err := requireOwnerAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if formula != FormulaInverseWeighted && formula != FormulaFlat {
+		return nil, fmt.Errorf("formula must be %s or %s, got %s", FormulaInverseWeighted, FormulaFlat, formula)
+	}
+	if standardBaseCost < 0 || goldBaseCost < 0 || platinumBaseCost < 0 || spreadWeight < 0 || targetWeight < 0 {
+		return nil, fmt.Errorf("base costs and weights must not be negative")
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	now := txTimestamp.AsTime()
+	effectiveFromTime, err := time.Parse(time.RFC3339, effectiveFrom)
+	if err != nil {
+		return nil, fmt.Errorf("effective from must be an RFC 3339 time: %v", err)
+	}
+	if effectiveFromTime.Before(now) {
+		return nil, fmt.Errorf("a pricing policy can not take effect in the past, %s is before %s", effectiveFrom, now.Format(time.RFC3339))
+	}
+
+	setBy, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client ID: %v", err)
+	}
+	policies, err := getPricingPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := PricingPolicy{
+		Version:       len(policies) + 1,
+		Formula:       formula,
+		BaseCosts:     BaseCosts{Standard: standardBaseCost, Gold: goldBaseCost, Platinum: platinumBaseCost},
+		SpreadWeight:  spreadWeight,
+		TargetWeight:  targetWeight,
+		EffectiveFrom: effectiveFromTime,
+		SetAt:         now,
+		SetBy:         setBy,
+	}
+	policyKey, err := ctx.GetStub().CreateCompositeKey(pricingPolicyObjectType, []string{fmt.Sprintf("%08d", policy.Version)})
+	if err != nil {
+		return nil, err
+	}
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(policyKey, policyJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	return &policy, nil
+}
+
+// GetPricingPolicy returns the pricing policy in force at the time of the transaction.
+func (s *SmartContract) GetPricingPolicy(ctx contractapi.TransactionContextInterface) (*PricingPolicy, error) {
+	return activePricingPolicy(ctx)
+}
+
+// GetPricingPolicyHistory returns every version of the pricing policy, the upcoming versions included.
+func (s *SmartContract) GetPricingPolicyHistory(ctx contractapi.TransactionContextInterface) ([]*PricingPolicy, error) {
+	return getPricingPolicies(ctx)
+}
+
+// evaluateSLA prices an SLA with the pricing policy in force at the time of the transaction. The parameters
+// are validated first, so that an SLA is never priced with a zero spread or target.
+func evaluateSLA(ctx contractapi.TransactionContextInterface, serviceLevel string, targetGrassLength float32, maxGrassLength float32, minGrassLength float32) (*SLAEvaluation, error) {
+	err := validateSLA(ctx, serviceLevel, targetGrassLength, maxGrassLength, minGrassLength)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := activePricingPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	monthlyCost, err := priceSLA(policy, serviceLevel, targetGrassLength, maxGrassLength, minGrassLength)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("Monthly cost: ", monthlyCost, " pricing policy version: ", policy.Version)
+	return &SLAEvaluation{MonthlyCost: monthlyCost, PricingPolicy: policy}, nil
+}
+
+// priceSLA returns the monthly cost of an SLA with valid parameters by a pricing policy.
+func priceSLA(policy *PricingPolicy, serviceLevel string, targetGrassLength float32, maxGrassLength float32, minGrassLength float32) (int, error) {
+	var baseCost float32
+	switch serviceLevel {
+	case "standard":
+		baseCost = policy.BaseCosts.Standard
+	case "gold":
+		baseCost = policy.BaseCosts.Gold
+	case "platinum":
+		baseCost = policy.BaseCosts.Platinum
+	default:
+		return 0, fmt.Errorf("invalid service level: %s", serviceLevel)
+	}
+
+	monthlyCost := baseCost
+	if policy.Formula == FormulaInverseWeighted {
+		spread := maxGrassLength - minGrassLength
+		fmt.Println("spread: ", spread)
+
+		// Invert the spread for cost calculation (larger spread, lower cost)
+		inverseSpread := 1.0 / spread
+		// Cost factor based on target length (shorter target, higher cost)
+		targetFactor := 1.0 / targetGrassLength
+
+		costFactor := (inverseSpread * policy.SpreadWeight) + (targetFactor * policy.TargetWeight)
+		fmt.Println("Cost factor: ", costFactor)
+
+		monthlyCost = baseCost * (costFactor + 1)
+	}
+	return int(monthlyCost), nil
+}
+
+// activePricingPolicy returns the pricing policy in force at the time of the transaction.
+func activePricingPolicy(ctx contractapi.TransactionContextInterface) (*PricingPolicy, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	now := txTimestamp.AsTime()
+
+	policies, err := getPricingPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return policyInForce(policies, now), nil
+}
+
+// policyInForce returns the policy with the latest EffectiveFrom that is not after now, the latest added
+// of them if several take effect at the same time, or the default policy when none is in force yet.
+func policyInForce(policies []*PricingPolicy, now time.Time) *PricingPolicy {
+	var active *PricingPolicy
+	for _, policy := range policies {
+		if policy.EffectiveFrom.After(now) {
+			continue
+		}
+		if active == nil || !policy.EffectiveFrom.Before(active.EffectiveFrom) {
+			active = policy
+		}
+	}
+	if active == nil {
+		policy := defaultPricingPolicy
+		return &policy
+	}
+	return active
+}
+
+func getPricingPolicies(ctx contractapi.TransactionContextInterface) ([]*PricingPolicy, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(pricingPolicyObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	policies := []*PricingPolicy{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var policy PricingPolicy
+		err = json.Unmarshal(queryResponse.Value, &policy)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, &policy)
+	}
+
+	return policies, nil
+}
+
+func requireOwnerAdmin(ctx contractapi.TransactionContextInterface) error {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	role, _, err := ctx.GetClientIdentity().GetAttributeValue(roleAttribute)
+	if err != nil {
+		return fmt.Errorf("failed to get client role: %v", err)
+	}
+	if mspID != ownerMSPID || role != roleOwnerAdmin {
+		return fmt.Errorf("only the %s of %s may change the pricing and the validation rules", roleOwnerAdmin, ownerMSPID)
+	}
+	return nil
+}
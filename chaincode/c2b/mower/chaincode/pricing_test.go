@@ -0,0 +1,82 @@
+package mower
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriceSLA(t *testing.T) {
+	weighted := &PricingPolicy{
+		Formula:      FormulaInverseWeighted,
+		BaseCosts:    BaseCosts{Standard: 50, Gold: 100, Platinum: 200},
+		SpreadWeight: 1,
+		TargetWeight: 2,
+	}
+	flat := &PricingPolicy{
+		Formula:   FormulaFlat,
+		BaseCosts: BaseCosts{Standard: 50, Gold: 100, Platinum: 200},
+	}
+
+	tests := []struct {
+		name         string
+		policy       *PricingPolicy
+		serviceLevel string
+		target       float32
+		max          float32
+		min          float32
+		want         int
+	}{
+		{"weighted standard", weighted, "standard", 8, 10, 6, 75},
+		{"weighted gold", weighted, "gold", 8, 10, 6, 150},
+		{"weighted platinum", weighted, "platinum", 8, 10, 6, 300},
+		{"narrow interval and short target cost more", weighted, "gold", 2, 2.5, 2, 400},
+		{"flat ignores the grass lengths", flat, "gold", 2, 2.5, 2, 100},
+		{"flat platinum", flat, "platinum", 8, 10, 6, 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := priceSLA(tt.policy, tt.serviceLevel, tt.target, tt.max, tt.min)
+			if err != nil {
+				t.Fatalf("priceSLA() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("priceSLA() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	_, err := priceSLA(weighted, "bronze", 8, 10, 6)
+	if err == nil {
+		t.Errorf("priceSLA() with an unknown service level returned no error")
+	}
+}
+
+func TestPolicyInForce(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	version := func(v int, effectiveFrom time.Time) *PricingPolicy {
+		return &PricingPolicy{Version: v, Formula: FormulaFlat, EffectiveFrom: effectiveFrom}
+	}
+
+	tests := []struct {
+		name        string
+		policies    []*PricingPolicy
+		wantVersion int
+	}{
+		{"no policies is the default", nil, 0},
+		{"only future policies is the default", []*PricingPolicy{version(1, now.Add(time.Second))}, 0},
+		{"effective at the transaction time", []*PricingPolicy{version(1, now)}, 1},
+		{"latest effective policy", []*PricingPolicy{version(1, now.AddDate(0, -2, 0)), version(2, now.AddDate(0, -1, 0)), version(3, now.AddDate(0, 1, 0))}, 2},
+		{"added later but effective earlier", []*PricingPolicy{version(1, now.AddDate(0, -1, 0)), version(2, now.AddDate(0, -2, 0))}, 1},
+		{"same effective time is the latest added", []*PricingPolicy{version(1, now.AddDate(0, -1, 0)), version(2, now.AddDate(0, -1, 0))}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policyInForce(tt.policies, now)
+			if got.Version != tt.wantVersion {
+				t.Errorf("policyInForce() = version %d, want version %d", got.Version, tt.wantVersion)
+			}
+		})
+	}
+}
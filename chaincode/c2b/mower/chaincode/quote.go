@@ -0,0 +1,143 @@
+package mower
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// quoteObjectType is the composite key prefix of quotes, keyed by quote ID.
+	quoteObjectType = "Quote"
+
+	// quoteValidity is how long a quote can be used to buy an SLA at the quoted price.
+	quoteValidity = 24 * time.Hour
+
+	QuoteStatusOpen = "Open"
+	QuoteStatusUsed = "Used"
+)
+
+// Quote is a binding price for an SLA with the given parameters. It can be used by one CreateSLA before
+// ExpiresAt, whatever the pricing policy is by then.
+type Quote struct {
+	ID                   string    `json:"ID"`
+	ServiceLevel         string    `json:"ServiceLevel"`
+	TargetGrassLength    float32   `json:"TargetGrassLength"`
+	MaxGrassLength       float32   `json:"MaxGrassLength"`
+	MinGrassLength       float32   `json:"MinGrassLength"`
+	MonthlyCost          int       `json:"MonthlyCost"`
+	PricingPolicyVersion int       `json:"PricingPolicyVersion"`
+	CreatedAt            time.Time `json:"CreatedAt"`
+	ExpiresAt            time.Time `json:"ExpiresAt"`
+	Status               string    `json:"Status"`
+	// SLAID is the SLA the quote was used for.
+	SLAID string `json:"SLAID,omitempty"`
+}
+
+// CreateQuote prices an SLA with the pricing policy in force and stores the price as a quote. The ID of
+// the quote is the ID of the transaction.
+func (s *SmartContract) CreateQuote(ctx contractapi.TransactionContextInterface, serviceLevel string, targetGrassLength float32, maxGrassLength float32, minGrassLength float32) (*Quote, error) {
+	evaluation, err := evaluateSLA(ctx, serviceLevel, targetGrassLength, maxGrassLength, minGrassLength)
+	if err != nil {
+		return nil, err
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	createdAt := txTimestamp.AsTime()
+	quote := Quote{
+		ID:                   ctx.GetStub().GetTxID(),
+		ServiceLevel:         serviceLevel,
+		TargetGrassLength:    targetGrassLength,
+		MaxGrassLength:       maxGrassLength,
+		MinGrassLength:       minGrassLength,
+		MonthlyCost:          evaluation.MonthlyCost,
+		PricingPolicyVersion: evaluation.PricingPolicy.Version,
+		CreatedAt:            createdAt,
+		ExpiresAt:            createdAt.Add(quoteValidity),
+		Status:               QuoteStatusOpen,
+	}
+	err = putQuote(ctx, &quote)
+	if err != nil {
+		return nil, err
+	}
+
+	return &quote, nil
+}
+
+// ReadQuote returns a quote.
+func (s *SmartContract) ReadQuote(ctx contractapi.TransactionContextInterface, quoteID string) (*Quote, error) {
+	return readQuote(ctx, quoteID)
+}
+
+// useQuote marks a quote as used by an SLA and returns it. The quote must be open, unexpired and for the
+// same parameters as the SLA.
+func useQuote(ctx contractapi.TransactionContextInterface, quoteID string, sla *SLA) (*Quote, error) {
+	quote, err := readQuote(ctx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Status != QuoteStatusOpen {
+		return nil, fmt.Errorf("the quote %s was already used for the SLA %s", quoteID, quote.SLAID)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	if !txTimestamp.AsTime().Before(quote.ExpiresAt) {
+		return nil, fmt.Errorf("the quote %s expired at %s, request a new quote", quoteID, quote.ExpiresAt.Format(time.RFC3339))
+	}
+	if quote.ServiceLevel != sla.ServiceLevel || quote.TargetGrassLength != sla.TargetGrassLength || quote.MaxGrassLength != sla.MaxGrassLength || quote.MinGrassLength != sla.MinGrassLength {
+		return nil, fmt.Errorf("the quote %s is for other SLA parameters than the SLA %s", quoteID, sla.ID)
+	}
+
+	quote.Status = QuoteStatusUsed
+	quote.SLAID = sla.ID
+	err = putQuote(ctx, quote)
+	if err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+func putQuote(ctx contractapi.TransactionContextInterface, quote *Quote) error {
+	quoteKey, err := ctx.GetStub().CreateCompositeKey(quoteObjectType, []string{quote.ID})
+	if err != nil {
+		return err
+	}
+	quoteJSON, err := json.Marshal(quote)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(quoteKey, quoteJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+	return nil
+}
+
+func readQuote(ctx contractapi.TransactionContextInterface, quoteID string) (*Quote, error) {
+	quoteKey, err := ctx.GetStub().CreateCompositeKey(quoteObjectType, []string{quoteID})
+	if err != nil {
+		return nil, err
+	}
+	quoteJSON, err := ctx.GetStub().GetState(quoteKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if quoteJSON == nil {
+		return nil, fmt.Errorf("the quote %s does not exist", quoteID)
+	}
+
+	var quote Quote
+	err = json.Unmarshal(quoteJSON, &quote)
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
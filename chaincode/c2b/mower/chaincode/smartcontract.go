@@ -3,8 +3,11 @@ package mower
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/nalle631/fabric-network/chaincode/events"
 )
 
 // SmartContract provides functions for managing an Asset
@@ -12,6 +15,9 @@ type SmartContract struct {
 	contractapi.Contract
 }
 
+// mowerRegistryChaincodeName is the chaincode the mowers are registered in, on the same channel.
+const mowerRegistryChaincodeName = "mower-registry"
+
 // Asset describes basic details of what makes up a simple asset
 // Insert struct field in alphabetic order => to achieve determinism across languages
 // golang keeps the order when marshal to json but doesn't order automatically
@@ -22,10 +28,30 @@ type SLA struct {
 	MaxGrassLength    float32 `json:"MaxGrassLength"`
 	MinGrassLength    float32 `json:"MinGrassLength"`
 	ID                string  `json:"ID"`
+	MowerSerial       string  `json:"MowerSerial,omitempty"`
+	// QuoteID is the quote AppraisedValue was taken from. It is empty when the SLA was bought without a quote
+	// or has been priced again since.
+	QuoteID string `json:"QuoteID,omitempty"`
+	// PricingPolicyVersion is the version of the pricing policy AppraisedValue was evaluated with.
+	PricingPolicyVersion int `json:"PricingPolicyVersion"`
+	// StartDate is when the SLA was bought and EndDate when its current term ends.
+	StartDate     time.Time `json:"StartDate"`
+	EndDate       time.Time `json:"EndDate"`
+	RenewalPolicy string    `json:"RenewalPolicy"`
+	Status        string    `json:"Status"`
+	// SuspendedAt is set while the SLA is Suspended, CancellationDate while it is PendingCancellation
+	// and TerminatedAt once it is Terminated.
+	SuspendedAt      *time.Time `json:"SuspendedAt,omitempty"`
+	CancellationDate *time.Time `json:"CancellationDate,omitempty"`
+	TerminatedAt     *time.Time `json:"TerminatedAt,omitempty"`
 }
 
-// CreateAsset issues a new asset to the world state with given details.
-func (s *SmartContract) CreateSLA(ctx contractapi.TransactionContextInterface, id string, serviceLevel string, targetgrasslength float32, maxgrasslength float32, mingrasslength float32) (*SLA, error) {
+// CreateSLA creates the SLA of the mower with serial number mowerSerial. SLAs are created through the
+// customer chaincode, which checks that the mower belongs to the customer, and the mower is checked to be
+// registered and active here as well for callers that call the mower chaincode directly. When quoteID is not empty, the SLA gets
+// the price of the quote, which must be open, unexpired and for the same parameters. The SLA starts right away
+// for a term of slaTermMonths, an empty renewalPolicy is AutoRenew.
+func (s *SmartContract) CreateSLA(ctx contractapi.TransactionContextInterface, id string, mowerSerial string, quoteID string, renewalPolicy string, serviceLevel string, targetgrasslength float32, maxgrasslength float32, mingrasslength float32) (*SLA, error) {
 	fmt.Println("In CreateSLA in mower contract")
 
 	exists, err := s.SLAExists(ctx, id)
@@ -37,6 +63,23 @@ func (s *SmartContract) CreateSLA(ctx contractapi.TransactionContextInterface, i
 		return nil, fmt.Errorf("the SLA %s already exists", id)
 	}
 
+	err = requireRegisteredMower(ctx, mowerSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	if renewalPolicy == "" {
+		renewalPolicy = RenewalPolicyAutoRenew
+	}
+	if renewalPolicy != RenewalPolicyAutoRenew && renewalPolicy != RenewalPolicyManual {
+		return nil, fmt.Errorf("renewal policy must be %s or %s, got %s", RenewalPolicyAutoRenew, RenewalPolicyManual, renewalPolicy)
+	}
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	startDate := txTimestamp.AsTime()
+
 	newSLA := SLA{
 		AppraisedValue:    0,
 		ID:                id,
@@ -44,18 +87,39 @@ func (s *SmartContract) CreateSLA(ctx contractapi.TransactionContextInterface, i
 		TargetGrassLength: targetgrasslength,
 		MaxGrassLength:    maxgrasslength,
 		MinGrassLength:    mingrasslength,
+		MowerSerial:       mowerSerial,
+		StartDate:         startDate,
+		EndDate:           startDate.AddDate(0, slaTermMonths, 0),
+		RenewalPolicy:     renewalPolicy,
+		Status:            SLAStatusActive,
 	}
 
 	fmt.Println("SLA before evaluation: ", newSLA)
 
-	slaValue, err := s.EvaluateSLA(ctx, newSLA.ServiceLevel, newSLA.TargetGrassLength, newSLA.MaxGrassLength, newSLA.MinGrassLength)
-	fmt.Println("slaValue: ", slaValue)
-	if err != nil {
-		fmt.Println("error evaluating SLA: ", err)
-		return nil, err
-	}
+	if quoteID != "" {
+		// the rules may have changed since the quote was made
+		err = validateSLA(ctx, newSLA.ServiceLevel, newSLA.TargetGrassLength, newSLA.MaxGrassLength, newSLA.MinGrassLength)
+		if err != nil {
+			return nil, err
+		}
+		quote, err := useQuote(ctx, quoteID, &newSLA)
+		if err != nil {
+			return nil, err
+		}
+		newSLA.AppraisedValue = quote.MonthlyCost
+		newSLA.PricingPolicyVersion = quote.PricingPolicyVersion
+		newSLA.QuoteID = quote.ID
+	} else {
+		evaluation, err := evaluateSLA(ctx, newSLA.ServiceLevel, newSLA.TargetGrassLength, newSLA.MaxGrassLength, newSLA.MinGrassLength)
+		if err != nil {
+			fmt.Println("error evaluating SLA: ", err)
+			return nil, err
+		}
+		fmt.Println("slaValue: ", evaluation.MonthlyCost)
 
-	newSLA.AppraisedValue = slaValue
+		newSLA.AppraisedValue = evaluation.MonthlyCost
+		newSLA.PricingPolicyVersion = evaluation.PricingPolicy.Version
+	}
 	fmt.Println("SLA after evaluation: ", newSLA)
 	slaJSON, err := json.Marshal(newSLA)
 	if err != nil {
@@ -87,6 +151,10 @@ func (s *SmartContract) ChangeServiceLevel(ctx contractapi.TransactionContextInt
 		fmt.Println("Error reading sla")
 		return nil, err
 	}
+	err = requireNotTerminated(sla)
+	if err != nil {
+		return nil, err
+	}
 
 	switch newServiceLevel {
 	case "standard":
@@ -102,13 +170,15 @@ func (s *SmartContract) ChangeServiceLevel(ctx contractapi.TransactionContextInt
 		return nil, fmt.Errorf("invalid service level")
 	}
 
-	newAppraisedValue, err := s.EvaluateSLA(ctx, sla.ServiceLevel, sla.TargetGrassLength, sla.MaxGrassLength, sla.MinGrassLength)
+	evaluation, err := evaluateSLA(ctx, sla.ServiceLevel, sla.TargetGrassLength, sla.MaxGrassLength, sla.MinGrassLength)
 	if err != nil {
 		fmt.Println("error evaluating SLA")
 		return nil, err
 	}
 
-	sla.AppraisedValue = newAppraisedValue
+	sla.AppraisedValue = evaluation.MonthlyCost
+	sla.PricingPolicyVersion = evaluation.PricingPolicy.Version
+	sla.QuoteID = ""
 
 	slaJSON, err := json.Marshal(sla)
 	if err != nil {
@@ -118,55 +188,37 @@ func (s *SmartContract) ChangeServiceLevel(ctx contractapi.TransactionContextInt
 	if err != nil {
 		return nil, err
 	}
+
+	// when the customer chaincode changes the service level, its own event is the one delivered
+	err = events.Emit(ctx.GetStub(), events.ServiceLevelChanged{
+		Version:        events.Version,
+		SLAID:          sla.ID,
+		ServiceLevel:   sla.ServiceLevel,
+		AppraisedValue: sla.AppraisedValue,
+	})
+	if err != nil {
+		return nil, err
+	}
 	return sla, nil
 }
 
-func (s *SmartContract) EvaluateSLA(ctx contractapi.TransactionContextInterface, serviceLevel string, targetGrassLength float32, maxGrassLength float32, minGrassLength float32) (int, error) {
-
-	spread := maxGrassLength - minGrassLength
-
-	fmt.Println("spread: ", spread)
-
-	// Invert the spread for cost calculation (larger spread, lower cost)
-	inverseSpread := 1.0 / spread
-
-	// Cost factor based on target length (shorter target, higher cost)
-	targetFactor := 1.0 / targetGrassLength
-
-	fmt.Println("Target factor: ", targetFactor)
-
-	// Combine factors with a weighting factor (adjust weight as needed)
-	costFactor := (inverseSpread * 0.7) + (targetFactor * 0.3)
-
-	fmt.Println("Cost factor: ", costFactor)
+// EvaluateSLA returns the monthly cost of an SLA with the given parameters, priced with the pricing policy in force.
+func (s *SmartContract) EvaluateSLA(ctx contractapi.TransactionContextInterface, serviceLevel string, targetGrassLength float32, maxGrassLength float32, minGrassLength float32) (*SLAEvaluation, error) {
+	return evaluateSLA(ctx, serviceLevel, targetGrassLength, maxGrassLength, minGrassLength)
+}
 
-	// Base cost (adjust as needed)
-	var baseCost float32
-	switch serviceLevel {
-	case "standard":
-		baseCost = 50
-	case "gold":
-		baseCost = 100
-	case "platinum":
-		baseCost = 200
+// ReadSLA returns an SLA, with the changes of status that have become due applied. The transactions that
+// change an SLA store it with these changes, and SettleSLA stores them without changing anything else.
+func (s *SmartContract) ReadSLA(ctx contractapi.TransactionContextInterface, id string) (*SLA, error) {
+	return readSLA(ctx, id)
+}
 
-	default:
-		return 0, fmt.Errorf("invalid service level: %s", serviceLevel)
+func readSLA(ctx contractapi.TransactionContextInterface, id string) (*SLA, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
 	}
 
-	// Calculate final cost
-	monthlyCost := baseCost * (costFactor + 1)
-
-	fmt.Println("Monthly cost: ", monthlyCost)
-
-	fmt.Println("Monthly cost integer: ", int(monthlyCost))
-
-	return int(monthlyCost), nil
-
-}
-
-// ReadAsset returns the asset stored in the world state with given id.
-func (s *SmartContract) ReadSLA(ctx contractapi.TransactionContextInterface, id string) (*SLA, error) {
 	assetJSON, err := ctx.GetStub().GetState(id)
 	if err != nil {
 		fmt.Println("Error getting world state")
@@ -184,6 +236,7 @@ func (s *SmartContract) ReadSLA(ctx contractapi.TransactionContextInterface, id
 		return nil, err
 	}
 
+	settleSLA(&asset, txTimestamp.AsTime())
 	fmt.Println("SLA: ", asset)
 	return &asset, nil
 }
@@ -200,18 +253,24 @@ func (s *SmartContract) UpdateTargetGrassLength(ctx contractapi.TransactionConte
 
 	// overwriting original asset with new asset
 	sla, readSLAerror := s.ReadSLA(ctx, id)
-	if err != nil {
+	if readSLAerror != nil {
 		return nil, readSLAerror
 	}
+	err = requireNotTerminated(sla)
+	if err != nil {
+		return nil, err
+	}
 
 	sla.TargetGrassLength = targetgrasslength
 
-	newValue, err := s.EvaluateSLA(ctx, sla.ServiceLevel, sla.TargetGrassLength, sla.MaxGrassLength, sla.MinGrassLength)
+	evaluation, err := evaluateSLA(ctx, sla.ServiceLevel, sla.TargetGrassLength, sla.MaxGrassLength, sla.MinGrassLength)
 	if err != nil {
 		return nil, err
 	}
 
-	sla.AppraisedValue = newValue
+	sla.AppraisedValue = evaluation.MonthlyCost
+	sla.PricingPolicyVersion = evaluation.PricingPolicy.Version
+	sla.QuoteID = ""
 	assetJSON, err := json.Marshal(sla)
 	if err != nil {
 		return nil, err
@@ -234,19 +293,25 @@ func (s *SmartContract) UpdateGrassLengthInterval(ctx contractapi.TransactionCon
 
 	// overwriting original asset with new asset
 	sla, readSLAerror := s.ReadSLA(ctx, id)
-	if err != nil {
+	if readSLAerror != nil {
 		return nil, readSLAerror
 	}
+	err = requireNotTerminated(sla)
+	if err != nil {
+		return nil, err
+	}
 
 	sla.MaxGrassLength = maxgrasslength
 	sla.MinGrassLength = mingrasslength
 
-	newValue, err := s.EvaluateSLA(ctx, sla.ServiceLevel, sla.TargetGrassLength, sla.MaxGrassLength, sla.MinGrassLength)
+	evaluation, err := evaluateSLA(ctx, sla.ServiceLevel, sla.TargetGrassLength, sla.MaxGrassLength, sla.MinGrassLength)
 	if err != nil {
 		return nil, err
 	}
 
-	sla.AppraisedValue = newValue
+	sla.AppraisedValue = evaluation.MonthlyCost
+	sla.PricingPolicyVersion = evaluation.PricingPolicy.Version
+	sla.QuoteID = ""
 	assetJSON, err := json.Marshal(sla)
 	if err != nil {
 		return nil, err
@@ -259,19 +324,6 @@ func (s *SmartContract) UpdateGrassLengthInterval(ctx contractapi.TransactionCon
 	return sla, nil
 }
 
-// DeleteAsset deletes an given asset from the world state.
-func (s *SmartContract) DeleteSLA(ctx contractapi.TransactionContextInterface, id string) error {
-	exists, err := s.SLAExists(ctx, id)
-	if err != nil {
-		return err
-	}
-	if !exists {
-		return fmt.Errorf("the asset %s does not exist", id)
-	}
-
-	return ctx.GetStub().DelState(id)
-}
-
 // AssetExists returns true when asset with given ID exists in world state
 func (s *SmartContract) SLAExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
 	slaJSON, err := ctx.GetStub().GetState(id)
@@ -282,8 +334,14 @@ func (s *SmartContract) SLAExists(ctx contractapi.TransactionContextInterface, i
 	return slaJSON != nil, nil
 }
 
-// GetAllAssets returns all assets found in world state
+// GetAllAssets returns all assets found in world state. It reads every SLA in one response, use
+// GetSLAsPage when there are many customers.
 func (s *SmartContract) GetAllSLA(ctx contractapi.TransactionContextInterface) ([]*SLA, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+
 	// range query with empty string for startKey and endKey does an
 	// open-ended query of all assets in the chaincode namespace.
 	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
@@ -304,8 +362,140 @@ func (s *SmartContract) GetAllSLA(ctx contractapi.TransactionContextInterface) (
 		if err != nil {
 			return nil, err
 		}
+		settleSLA(&asset, txTimestamp.AsTime())
 		slas = append(slas, &asset)
 	}
 
 	return slas, nil
 }
+
+// maxSLAPageSize is the largest page GetSLAsPage returns.
+const maxSLAPageSize = 100
+
+// SLAPage is one page of SLAs. Bookmark is passed to GetSLAsPage to read the next page, and is empty after
+// the last page. FetchedRecordsCount is the number of records read for the page, which is more than the
+// number of SLAs when a filter is used.
+type SLAPage struct {
+	SLAs                []*SLA `json:"SLAs"`
+	Bookmark            string `json:"Bookmark"`
+	FetchedRecordsCount int32  `json:"FetchedRecordsCount"`
+}
+
+// GetSLAsPage returns a page of at most pageSize SLAs, starting at bookmark or at the first SLA when
+// bookmark is empty. Only SLAs with serviceLevel and a monthly cost between minPrice and maxPrice are
+// returned, an empty serviceLevel and a maxPrice of 0 match every SLA. The SLAs are read until pageSize
+// of them match the filters, so only the last page holds fewer. The SLAs are returned with the changes of
+// status that have become due applied.
+func (s *SmartContract) GetSLAsPage(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string, serviceLevel string, minPrice int, maxPrice int) (*SLAPage, error) {
+	if pageSize < 1 || pageSize > maxSLAPageSize {
+		return nil, fmt.Errorf("page size must be between 1 and %d, got %d", maxSLAPageSize, pageSize)
+	}
+	if minPrice < 0 || maxPrice < 0 {
+		return nil, fmt.Errorf("price range can not be negative")
+	}
+	if maxPrice != 0 && maxPrice < minPrice {
+		return nil, fmt.Errorf("max price %d is lower than min price %d", maxPrice, minPrice)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+
+	// the bookmark is the key of the first SLA of the page
+	resultsIterator, err := ctx.GetStub().GetStateByRange(bookmark, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	page := SLAPage{SLAs: []*SLA{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if int32(len(page.SLAs)) == pageSize {
+			page.Bookmark = queryResponse.Key
+			break
+		}
+		page.FetchedRecordsCount++
+
+		var sla SLA
+		err = json.Unmarshal(queryResponse.Value, &sla)
+		if err != nil {
+			return nil, err
+		}
+		settleSLA(&sla, txTimestamp.AsTime())
+		if serviceLevel != "" && sla.ServiceLevel != serviceLevel {
+			continue
+		}
+		if sla.AppraisedValue < minPrice || (maxPrice != 0 && sla.AppraisedValue > maxPrice) {
+			continue
+		}
+		page.SLAs = append(page.SLAs, &sla)
+	}
+
+	return &page, nil
+}
+
+// SLAVersion is one version of an SLA on the ledger. SLA is not set on the version that deleted it.
+type SLAVersion struct {
+	TxID      string    `json:"TxID"`
+	Timestamp time.Time `json:"Timestamp"`
+	IsDelete  bool      `json:"IsDelete"`
+	SLA       *SLA      `json:"SLA,omitempty"`
+}
+
+// GetSLAHistory returns every version of an SLA, oldest first, for example to see when its price changed.
+func (s *SmartContract) GetSLAHistory(ctx contractapi.TransactionContextInterface, id string) ([]*SLAVersion, error) {
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	versions := []*SLAVersion{}
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		version := SLAVersion{
+			TxID:      modification.TxId,
+			Timestamp: modification.Timestamp.AsTime(),
+			IsDelete:  modification.IsDelete,
+		}
+		if !modification.IsDelete {
+			version.SLA = &SLA{}
+			err = json.Unmarshal(modification.Value, version.SLA)
+			if err != nil {
+				return nil, err
+			}
+		}
+		versions = append(versions, &version)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("the SLA %s has no history", id)
+	}
+
+	// the history is returned newest first
+	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+		versions[i], versions[j] = versions[j], versions[i]
+	}
+	return versions, nil
+}
+
+// requireRegisteredMower fails unless the mower is registered and active in the mower registry.
+func requireRegisteredMower(ctx contractapi.TransactionContextInterface, mowerSerial string) error {
+	if mowerSerial == "" {
+		return fmt.Errorf("an SLA must cover a mower, the mower serial can not be empty")
+	}
+	invokeArgs := [][]byte{[]byte("ValidateMower"), []byte(mowerSerial), []byte("")}
+	response := ctx.GetStub().InvokeChaincode(mowerRegistryChaincodeName, invokeArgs, ctx.GetStub().GetChannelID())
+	if response.Status != shim.OK {
+		return fmt.Errorf("mower %s can not get an SLA: %s", mowerSerial, response.Message)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package mower
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// validationRulesObjectType is the composite key prefix of the validation rules, keyed by service level.
+	validationRulesObjectType = "ValidationRules"
+
+	// ValidationErrorPrefix starts the message of a ValidationError, the JSON of its field errors follows it.
+	ValidationErrorPrefix = "invalid SLA parameters: "
+)
+
+// serviceLevels are the service levels an SLA can have.
+var serviceLevels = []string{"standard", "gold", "platinum"}
+
+// ValidationRules are the bounds the grass lengths of the SLAs of a service level must be within.
+type ValidationRules struct {
+	ServiceLevel string `json:"ServiceLevel"`
+	// MinGrassLength and MaxGrassLength are the lowest and highest grass length an SLA may ask for.
+	MinGrassLength float32 `json:"MinGrassLength"`
+	MaxGrassLength float32 `json:"MaxGrassLength"`
+	// MinSpread is how much wider than the min grass length the max grass length must at least be.
+	MinSpread float32    `json:"MinSpread"`
+	SetAt     *time.Time `json:"SetAt,omitempty"`
+	SetBy     string     `json:"SetBy,omitempty"`
+}
+
+// FieldError is why one parameter of an SLA is invalid.
+type FieldError struct {
+	Field   string `json:"Field"`
+	Message string `json:"Message"`
+}
+
+// ValidationError lists every invalid parameter of an SLA. Its message is ValidationErrorPrefix followed
+// by the JSON of the error, so that applications can tell the client which fields to correct.
+type ValidationError struct {
+	Errors []FieldError `json:"Errors"`
+}
+
+func (e *ValidationError) Error() string {
+	errorsJSON, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Sprintf("%s%v", ValidationErrorPrefix, e.Errors)
+	}
+	return ValidationErrorPrefix + string(errorsJSON)
+}
+
+func (e *ValidationError) add(field string, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// defaultValidationRules are used for a service level until its rules are set.
+var defaultValidationRules = ValidationRules{
+	MinGrassLength: 1,
+	MaxGrassLength: 30,
+	MinSpread:      0.5,
+}
+
+// SetValidationRules sets the bounds of the grass lengths of the SLAs of a service level. Existing SLAs
+// are checked against the rules the next time they are changed.
+func (s *SmartContract) SetValidationRules(ctx contractapi.TransactionContextInterface, serviceLevel string, minGrassLength float32, maxGrassLength float32, minSpread float32) (*ValidationRules, error) {
+	err := requireOwnerAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	validationErr := &ValidationError{}
+	if !isServiceLevel(serviceLevel) {
+		validationErr.add("ServiceLevel", "must be one of %v", serviceLevels)
+	}
+	if !(minGrassLength > 0) {
+		validationErr.add("MinGrassLength", "must be greater than 0")
+	}
+	if !(minSpread >= 0) {
+		validationErr.add("MinSpread", "must not be negative")
+	}
+	if !(maxGrassLength-minGrassLength >= minSpread) {
+		validationErr.add("MaxGrassLength", "must be at least MinGrassLength + MinSpread")
+	}
+	if len(validationErr.Errors) > 0 {
+		return nil, validationErr
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	setAt := txTimestamp.AsTime()
+	setBy, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client ID: %v", err)
+	}
+
+	rules := ValidationRules{
+		ServiceLevel:   serviceLevel,
+		MinGrassLength: minGrassLength,
+		MaxGrassLength: maxGrassLength,
+		MinSpread:      minSpread,
+		SetAt:          &setAt,
+		SetBy:          setBy,
+	}
+	rulesKey, err := ctx.GetStub().CreateCompositeKey(validationRulesObjectType, []string{serviceLevel})
+	if err != nil {
+		return nil, err
+	}
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(rulesKey, rulesJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	return &rules, nil
+}
+
+// GetValidationRules returns the validation rules of every service level.
+func (s *SmartContract) GetValidationRules(ctx contractapi.TransactionContextInterface) ([]*ValidationRules, error) {
+	allRules := []*ValidationRules{}
+	for _, serviceLevel := range serviceLevels {
+		rules, err := getValidationRules(ctx, serviceLevel)
+		if err != nil {
+			return nil, err
+		}
+		allRules = append(allRules, rules)
+	}
+	return allRules, nil
+}
+
+// validateSLA returns a *ValidationError with every parameter of an SLA that breaks the rules of its
+// service level.
+func validateSLA(ctx contractapi.TransactionContextInterface, serviceLevel string, targetGrassLength float32, maxGrassLength float32, minGrassLength float32) error {
+	validationErr := &ValidationError{}
+	if !isServiceLevel(serviceLevel) {
+		validationErr.add("ServiceLevel", "must be one of %v", serviceLevels)
+		return validationErr
+	}
+
+	rules, err := getValidationRules(ctx, serviceLevel)
+	if err != nil {
+		return err
+	}
+
+	return rules.check(targetGrassLength, maxGrassLength, minGrassLength)
+}
+
+// check returns a *ValidationError with every grass length of an SLA that breaks the rules. The checks are
+// written so that NaN fails them.
+func (rules *ValidationRules) check(targetGrassLength float32, maxGrassLength float32, minGrassLength float32) error {
+	validationErr := &ValidationError{}
+	if !(minGrassLength >= rules.MinGrassLength && minGrassLength <= rules.MaxGrassLength) {
+		validationErr.add("MinGrassLength", "must be between %v and %v for %s", rules.MinGrassLength, rules.MaxGrassLength, rules.ServiceLevel)
+	}
+	if !(maxGrassLength >= rules.MinGrassLength && maxGrassLength <= rules.MaxGrassLength) {
+		validationErr.add("MaxGrassLength", "must be between %v and %v for %s", rules.MinGrassLength, rules.MaxGrassLength, rules.ServiceLevel)
+	} else if !(maxGrassLength-minGrassLength >= rules.MinSpread) || !(maxGrassLength > minGrassLength) {
+		validationErr.add("MaxGrassLength", "must be greater than MinGrassLength by at least %v for %s", rules.MinSpread, rules.ServiceLevel)
+	}
+	if !(targetGrassLength > 0) {
+		validationErr.add("TargetGrassLength", "must be greater than 0")
+	} else if !(targetGrassLength >= minGrassLength && targetGrassLength <= maxGrassLength) {
+		validationErr.add("TargetGrassLength", "must be between MinGrassLength %v and MaxGrassLength %v", minGrassLength, maxGrassLength)
+	}
+
+	if len(validationErr.Errors) > 0 {
+		return validationErr
+	}
+	return nil
+}
+
+func getValidationRules(ctx contractapi.TransactionContextInterface, serviceLevel string) (*ValidationRules, error) {
+	rulesKey, err := ctx.GetStub().CreateCompositeKey(validationRulesObjectType, []string{serviceLevel})
+	if err != nil {
+		return nil, err
+	}
+	rulesJSON, err := ctx.GetStub().GetState(rulesKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	rules := defaultValidationRules
+	if rulesJSON != nil {
+		err = json.Unmarshal(rulesJSON, &rules)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rules.ServiceLevel = serviceLevel
+	return &rules, nil
+}
+
+func isServiceLevel(serviceLevel string) bool {
+	for _, level := range serviceLevels {
+		if level == serviceLevel {
+			return true
+		}
+	}
+	return false
+}
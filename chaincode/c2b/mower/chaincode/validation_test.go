@@ -0,0 +1,67 @@
+package mower
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidationRulesCheck(t *testing.T) {
+	rules := defaultValidationRules
+	rules.ServiceLevel = "gold"
+	noSpread := ValidationRules{ServiceLevel: "gold", MinGrassLength: 1, MaxGrassLength: 30, MinSpread: 0}
+	nan := float32(math.NaN())
+
+	tests := []struct {
+		name       string
+		rules      ValidationRules
+		target     float32
+		max        float32
+		min        float32
+		wantFields []string
+	}{
+		{"valid", rules, 10, 20, 5, nil},
+		{"at the lowest grass length", rules, 1, 1.5, 1, nil},
+		{"at the highest grass length", rules, 30, 30, 29.5, nil},
+		{"target at the interval bounds", rules, 20, 20, 5, nil},
+		{"min below the lowest grass length", rules, 10, 20, 0.5, []string{"MinGrassLength"}},
+		{"max above the highest grass length", rules, 10, 30.5, 5, []string{"MaxGrassLength"}},
+		{"spread below the min spread", rules, 10, 10.25, 10, []string{"MaxGrassLength"}},
+		{"max below min", rules, 10, 5, 20, []string{"MaxGrassLength", "TargetGrassLength"}},
+		{"max equal to min without a min spread", noSpread, 10, 10, 10, []string{"MaxGrassLength"}},
+		{"target of 0", rules, 0, 20, 5, []string{"TargetGrassLength"}},
+		{"target above the interval", rules, 25, 20, 5, []string{"TargetGrassLength"}},
+		{"target below the interval", rules, 4, 20, 5, []string{"TargetGrassLength"}},
+		{"NaN min", rules, 10, 20, nan, []string{"MinGrassLength", "MaxGrassLength", "TargetGrassLength"}},
+		{"NaN target", rules, nan, 20, 5, []string{"TargetGrassLength"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.check(tt.target, tt.max, tt.min)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("check() returned error: %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("check() = %v, want a *ValidationError", err)
+			}
+			fields := []string{}
+			for _, fieldErr := range validationErr.Errors {
+				fields = append(fields, fieldErr.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("check() failed fields %v, want %v", fields, tt.wantFields)
+			}
+			if !strings.HasPrefix(err.Error(), ValidationErrorPrefix) {
+				t.Errorf("check() error %q does not start with %q", err.Error(), ValidationErrorPrefix)
+			}
+		})
+	}
+}
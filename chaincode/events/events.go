@@ -0,0 +1,336 @@
+// Package events defines the chaincode events of the project. Every event is emitted with its name as
+// the event name and the JSON of its payload type as the payload, so that applications can listen for
+// the changes they need instead of polling.
+//
+// A transaction has at most one event. When a chaincode is called by another chaincode, only the event of
+// the chaincode the transaction was sent to is delivered, for example the JobTaken event of the job contract
+// and not the ServiceJobCreated event of the service chaincode it calls.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Version is the version of the payloads, it is sent in every payload. Fields are only added within a
+// version, a payload that changes or removes a field gets a new version.
+const Version = 1
+
+// Event names, one for every payload type.
+const (
+	GeneralContractCreatedName = "GeneralContractCreated"
+	JobTakenName               = "JobTaken"
+	JobSubmittedName           = "JobSubmitted"
+	JobCompletedName           = "JobCompleted"
+	JobCancelledName           = "JobCancelled"
+	JobReleasedName            = "JobReleased"
+	JobReassignedName          = "JobReassigned"
+	JobStatusChangedName       = "JobStatusChanged"
+	PayoutPeriodClosedName     = "PayoutPeriodClosed"
+	JobAuthorityChangedName    = "JobAuthorityChanged"
+	ServiceJobCreatedName      = "ServiceJobCreated"
+	SLACreatedName             = "SLACreated"
+	ServiceLevelChangedName    = "ServiceLevelChanged"
+	SLARemovedName             = "SLARemoved"
+	SLAStatusChangedName       = "SLAStatusChanged"
+	MowerRegisteredName        = "MowerRegistered"
+	MowerTransferredName       = "MowerTransferred"
+	MowerDecommissionedName    = "MowerDecommissioned"
+	InvoiceIssuedName          = "InvoiceIssued"
+	InvoicePaidName            = "InvoicePaid"
+)
+
+// Event is the payload of a chaincode event.
+type Event interface {
+	EventName() string
+}
+
+// Stub is the part of the chaincode stub that sets the event of a transaction.
+type Stub interface {
+	SetEvent(name string, payload []byte) error
+}
+
+// GeneralContractCreated is emitted by the job contract when a technician organisation creates its general contract.
+type GeneralContractCreated struct {
+	Version      int    `json:"Version"`
+	TechnicianID string `json:"TechnicianID"`
+}
+
+// JobTaken is emitted by the job contract when a technician takes the job of a work order.
+type JobTaken struct {
+	Version       int       `json:"Version"`
+	TechnicianID  string    `json:"TechnicianID"`
+	JobID         string    `json:"JobID"`
+	EventType     string    `json:"EventType"`
+	Mower         string    `json:"Mower"`
+	Address       string    `json:"Address"`
+	ServiceLevel  string    `json:"ServiceLevel"`
+	SLAID         string    `json:"SLAID,omitempty"`
+	Deadline      time.Time `json:"Deadline"`
+	JobPay        int       `json:"JobPay"`
+	InspectionPay int       `json:"InspectionPay"`
+	RateVersion   int       `json:"RateVersion"`
+}
+
+// JobSubmitted is emitted by the job contract when a technician submits a job that waits for an inspection.
+type JobSubmitted struct {
+	Version      int       `json:"Version"`
+	TechnicianID string    `json:"TechnicianID"`
+	JobID        string    `json:"JobID"`
+	EvidenceHash string    `json:"EvidenceHash"`
+	SubmittedAt  time.Time `json:"SubmittedAt"`
+}
+
+// JobCompleted is emitted by the job contract when a job is done, after its inspection or right away
+// for a self-reported service. Verdict is empty when the job was not inspected.
+type JobCompleted struct {
+	Version      int       `json:"Version"`
+	TechnicianID string    `json:"TechnicianID"`
+	JobID        string    `json:"JobID"`
+	Verdict      string    `json:"Verdict,omitempty"`
+	Payout       int       `json:"Payout"`
+	Penalty      int       `json:"Penalty"`
+	DaysLate     int       `json:"DaysLate"`
+	CompletedAt  time.Time `json:"CompletedAt"`
+}
+
+// JobCancelled is emitted by the job contract when the owner cancels a job. Compensation is paid out like
+// the pay of a finished job.
+type JobCancelled struct {
+	Version      int       `json:"Version"`
+	TechnicianID string    `json:"TechnicianID"`
+	JobID        string    `json:"JobID"`
+	Compensation int       `json:"Compensation"`
+	CancelledAt  time.Time `json:"CancelledAt"`
+}
+
+// JobReleased is emitted by the job contract when a technician gives back a job, which can then be taken again.
+type JobReleased struct {
+	Version      int       `json:"Version"`
+	TechnicianID string    `json:"TechnicianID"`
+	JobID        string    `json:"JobID"`
+	ReleasedAt   time.Time `json:"ReleasedAt"`
+}
+
+// JobReassigned is emitted by the job contract when the owner moves a job to another technician.
+type JobReassigned struct {
+	Version          int       `json:"Version"`
+	FromTechnicianID string    `json:"FromTechnicianID"`
+	ToTechnicianID   string    `json:"ToTechnicianID"`
+	JobID            string    `json:"JobID"`
+	ReassignedAt     time.Time `json:"ReassignedAt"`
+}
+
+// JobStatusChanged is emitted by the job contract when a technician starts travelling to a job, starts
+// working on it or fails it, and when the owner expires it. Taking, submitting, completing, cancelling,
+// releasing and reassigning a job have their own events.
+type JobStatusChanged struct {
+	Version      int       `json:"Version"`
+	TechnicianID string    `json:"TechnicianID"`
+	JobID        string    `json:"JobID"`
+	From         string    `json:"From"`
+	To           string    `json:"To"`
+	ChangedAt    time.Time `json:"ChangedAt"`
+}
+
+// PayoutPeriodClosed is emitted by the job contract when the owner closes the payout period of a technician.
+type PayoutPeriodClosed struct {
+	Version      int       `json:"Version"`
+	TechnicianID string    `json:"TechnicianID"`
+	Period       string    `json:"Period"`
+	Amount       int       `json:"Amount"`
+	Jobs         []string  `json:"Jobs"`
+	ClosedAt     time.Time `json:"ClosedAt"`
+}
+
+// JobAuthorityChanged is emitted by the job contract when the owner grants or revokes the authority of a
+// technician to take jobs of a job type. Action is granted or revoked.
+type JobAuthorityChanged struct {
+	Version      int       `json:"Version"`
+	TechnicianID string    `json:"TechnicianID"`
+	JobType      string    `json:"JobType"`
+	Action       string    `json:"Action"`
+	ChangedAt    time.Time `json:"ChangedAt"`
+}
+
+// ServiceJobCreated is emitted by a service chaincode when it creates a job.
+type ServiceJobCreated struct {
+	Version     int       `json:"Version"`
+	Service     string    `json:"Service"`
+	JobID       string    `json:"JobID"`
+	Mower       string    `json:"Mower"`
+	Address     string    `json:"Address"`
+	Deadline    time.Time `json:"Deadline"`
+	RateVersion int       `json:"RateVersion"`
+}
+
+// SLACreated is emitted by the customer chaincode when a customer buys an SLA.
+type SLACreated struct {
+	Version        int    `json:"Version"`
+	CustomerID     string `json:"CustomerID"`
+	SLAID          string `json:"SLAID"`
+	ServiceLevel   string `json:"ServiceLevel"`
+	AppraisedValue int    `json:"AppraisedValue"`
+}
+
+// ServiceLevelChanged is emitted when the service level of an SLA changes. CustomerID is empty when
+// the mower chaincode was called directly.
+type ServiceLevelChanged struct {
+	Version        int    `json:"Version"`
+	CustomerID     string `json:"CustomerID,omitempty"`
+	SLAID          string `json:"SLAID"`
+	ServiceLevel   string `json:"ServiceLevel"`
+	AppraisedValue int    `json:"AppraisedValue"`
+}
+
+// SLARemoved is emitted when an SLA is terminated right away, the SLA is kept with the status Terminated.
+// CustomerID is empty when the mower chaincode was called directly.
+type SLARemoved struct {
+	Version    int    `json:"Version"`
+	CustomerID string `json:"CustomerID,omitempty"`
+	SLAID      string `json:"SLAID"`
+}
+
+// SLAStatusChanged is emitted when an SLA is suspended, resumed, renewed or cancelled with notice, and when
+// SettleSLA stores a change of status that has become due. CustomerID is empty when the mower chaincode was
+// called directly.
+type SLAStatusChanged struct {
+	Version          int        `json:"Version"`
+	CustomerID       string     `json:"CustomerID,omitempty"`
+	SLAID            string     `json:"SLAID"`
+	Status           string     `json:"Status"`
+	EndDate          time.Time  `json:"EndDate"`
+	CancellationDate *time.Time `json:"CancellationDate,omitempty"`
+}
+
+// MowerRegistered is emitted by the mower registry when a mower is registered to a customer.
+type MowerRegistered struct {
+	Version      int    `json:"Version"`
+	SerialNumber string `json:"SerialNumber"`
+	Model        string `json:"Model"`
+	CustomerID   string `json:"CustomerID"`
+}
+
+// MowerTransferred is emitted by the mower registry when a mower is transferred to another customer.
+type MowerTransferred struct {
+	Version        int    `json:"Version"`
+	SerialNumber   string `json:"SerialNumber"`
+	FromCustomerID string `json:"FromCustomerID"`
+	ToCustomerID   string `json:"ToCustomerID"`
+}
+
+// MowerDecommissioned is emitted by the mower registry when a mower is taken out of service.
+type MowerDecommissioned struct {
+	Version      int    `json:"Version"`
+	SerialNumber string `json:"SerialNumber"`
+	CustomerID   string `json:"CustomerID"`
+}
+
+// InvoiceIssued is emitted by the customer chaincode when it invoices a customer for a period.
+type InvoiceIssued struct {
+	Version    int       `json:"Version"`
+	CustomerID string    `json:"CustomerID"`
+	Period     string    `json:"Period"`
+	Total      int       `json:"Total"`
+	DueDate    time.Time `json:"DueDate"`
+}
+
+// InvoicePaid is emitted by the customer chaincode when an invoice is marked as paid.
+type InvoicePaid struct {
+	Version    int       `json:"Version"`
+	CustomerID string    `json:"CustomerID"`
+	Period     string    `json:"Period"`
+	Total      int       `json:"Total"`
+	PaidAt     time.Time `json:"PaidAt"`
+}
+
+func (GeneralContractCreated) EventName() string { return GeneralContractCreatedName }
+func (JobTaken) EventName() string               { return JobTakenName }
+func (JobSubmitted) EventName() string           { return JobSubmittedName }
+func (JobCompleted) EventName() string           { return JobCompletedName }
+func (JobCancelled) EventName() string           { return JobCancelledName }
+func (JobReleased) EventName() string            { return JobReleasedName }
+func (JobReassigned) EventName() string          { return JobReassignedName }
+func (JobStatusChanged) EventName() string       { return JobStatusChangedName }
+func (PayoutPeriodClosed) EventName() string     { return PayoutPeriodClosedName }
+func (JobAuthorityChanged) EventName() string    { return JobAuthorityChangedName }
+func (ServiceJobCreated) EventName() string      { return ServiceJobCreatedName }
+func (SLACreated) EventName() string             { return SLACreatedName }
+func (ServiceLevelChanged) EventName() string    { return ServiceLevelChangedName }
+func (SLARemoved) EventName() string             { return SLARemovedName }
+func (SLAStatusChanged) EventName() string       { return SLAStatusChangedName }
+func (MowerRegistered) EventName() string        { return MowerRegisteredName }
+func (MowerTransferred) EventName() string       { return MowerTransferredName }
+func (MowerDecommissioned) EventName() string    { return MowerDecommissionedName }
+func (InvoiceIssued) EventName() string          { return InvoiceIssuedName }
+func (InvoicePaid) EventName() string            { return InvoicePaidName }
+
+// Emit sets event as the event of the transaction. The caller sets the Version of the payload.
+func Emit(stub Stub, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	err = stub.SetEvent(event.EventName(), payload)
+	if err != nil {
+		return fmt.Errorf("failed to set event %s: %v", event.EventName(), err)
+	}
+	return nil
+}
+
+// Decode returns the payload of a chaincode event as its payload type, for example *JobTaken for a
+// JobTaken event.
+func Decode(name string, payload []byte) (Event, error) {
+	var event Event
+	switch name {
+	case GeneralContractCreatedName:
+		event = &GeneralContractCreated{}
+	case JobTakenName:
+		event = &JobTaken{}
+	case JobSubmittedName:
+		event = &JobSubmitted{}
+	case JobCompletedName:
+		event = &JobCompleted{}
+	case JobCancelledName:
+		event = &JobCancelled{}
+	case JobReleasedName:
+		event = &JobReleased{}
+	case JobReassignedName:
+		event = &JobReassigned{}
+	case JobStatusChangedName:
+		event = &JobStatusChanged{}
+	case PayoutPeriodClosedName:
+		event = &PayoutPeriodClosed{}
+	case JobAuthorityChangedName:
+		event = &JobAuthorityChanged{}
+	case ServiceJobCreatedName:
+		event = &ServiceJobCreated{}
+	case SLACreatedName:
+		event = &SLACreated{}
+	case ServiceLevelChangedName:
+		event = &ServiceLevelChanged{}
+	case SLARemovedName:
+		event = &SLARemoved{}
+	case SLAStatusChangedName:
+		event = &SLAStatusChanged{}
+	case MowerRegisteredName:
+		event = &MowerRegistered{}
+	case MowerTransferredName:
+		event = &MowerTransferred{}
+	case MowerDecommissionedName:
+		event = &MowerDecommissioned{}
+	case InvoiceIssuedName:
+		event = &InvoiceIssued{}
+	case InvoicePaidName:
+		event = &InvoicePaid{}
+	default:
+		return nil, fmt.Errorf("unknown event %s", name)
+	}
+
+	err := json.Unmarshal(payload, event)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s event: %v", name, err)
+	}
+	return event, nil
+}
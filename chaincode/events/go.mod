@@ -0,0 +1,3 @@
+module github.com/nalle631/fabric-network/chaincode/events
+
+go 1.21